@@ -0,0 +1,80 @@
+/*
+ * Copyright © 2023 LICHENS http://www.lichens.io
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/lichensio/slichens/pkg/anonymize"
+	"github.com/lichensio/slichens/pkg/lichens"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// anonymizeCmd represents the anonymize command
+var anonymizeCmd = &cobra.Command{
+	Use:   "anonymize",
+	Short: "Anonymise siretta surveys before sharing them",
+	Long: `Anonymise siretta surveys before sharing them outside the company: drop or hash the IMEI,
+shift every timestamp by the same random offset and optionally replace CellID, LAC/TAC and PCI by
+pseudonyms that are consistent across the whole batch. The surveys are written as siretta csv files.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filename, _ := cmd.Flags().GetString("filename")
+		output, _ := cmd.Flags().GetString("output")
+		imei, _ := cmd.Flags().GetString("imei")
+		shift, _ := cmd.Flags().GetDuration("shift")
+		maxShift, _ := cmd.Flags().GetDuration("max-shift")
+		cells, _ := cmd.Flags().GetBool("cells")
+		key, _ := cmd.Flags().GetString("key")
+
+		if filename == "" || output == "" {
+			fmt.Println("survey file name and output directory required")
+			return
+		}
+		imeiMode, err := lichens.ParseIMEIMode(imei)
+		if err != nil {
+			fmt.Println("Error in imei:", err)
+			return
+		}
+		opts, err := parseOptions()
+		if err != nil {
+			fmt.Println("Error in reader options:", err)
+			return
+		}
+
+		anonymizeOpts := lichens.AnonymizeOptions{
+			IMEI:       imeiMode,
+			Key:        []byte(key),
+			Shift:      shift,
+			FixedShift: cmd.Flags().Changed("shift"),
+			MaxShift:   maxShift,
+			Cells:      cells,
+		}
+		written, err := anonymize.ProcessAnonymize(filename, output, opts, anonymizeOpts)
+		for _, name := range written {
+			fmt.Println(name)
+		}
+		if err != nil {
+			fmt.Println("anonymize.ProcessAnonymize error:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(anonymizeCmd)
+
+	anonymizeCmd.PersistentFlags().String("filename", "", "siretta filename Lxxxxx.csv, directory or glob, - for stdin")
+	anonymizeCmd.PersistentFlags().String("output", "", "directory the anonymised surveys are written to")
+	anonymizeCmd.PersistentFlags().String("imei", "drop", "IMEI handling: drop, hash or keep")
+	anonymizeCmd.PersistentFlags().Duration("shift", 0, "time shift applied to the timestamps, 0 for none. Default a random shift within max-shift")
+	anonymizeCmd.PersistentFlags().Duration("max-shift", 90*24*time.Hour, "largest random time shift, in either direction")
+	anonymizeCmd.PersistentFlags().Bool("cells", false, "replace CellID, LAC/TAC and PCI by pseudonyms")
+	anonymizeCmd.PersistentFlags().String("key", "", "secret for the IMEI hash and cell pseudonyms, to get the same pseudonyms across runs. Default random")
+}
@@ -12,6 +12,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/lichensio/slichens/pkg/attenuation"
+	"github.com/lichensio/slichens/pkg/lichens"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +25,7 @@ var attenuationCmd = &cobra.Command{
 		out, errOut := cmd.Flags().GetString("outfile")
 		in, errIn := cmd.Flags().GetString("infile")
 		primarySortColumn, errSort := cmd.Flags().GetString("primarySortColumn")
+		network, errNetwork := cmd.Flags().GetString("network")
 
 		// Check for errors when fetching flags
 		if errOut != nil {
@@ -38,9 +40,40 @@ var attenuationCmd = &cobra.Command{
 			fmt.Printf("Error getting primarySortColumn: %v\n", errSort)
 			return
 		}
+		if errNetwork != nil {
+			fmt.Printf("Error getting network: %v\n", errNetwork)
+			return
+		}
+		networkType, err := networkFlag(network)
+		if err != nil {
+			fmt.Printf("Error in network: %v\n", err)
+			return
+		}
+
+		if out == lichens.StdinName && in == lichens.StdinName {
+			fmt.Println("Only one of outfile and infile can be read from stdin")
+			return
+		}
+
+		opts, err := parseOptions()
+		if err != nil {
+			fmt.Printf("Error in reader options: %v\n", err)
+			return
+		}
+
+		statOpts, err := statOptions(cmd)
+		if err != nil {
+			fmt.Printf("Error in statistics options: %v\n", err)
+			return
+		}
+		deltaOpts, err := deltaOptions(cmd)
+		if err != nil {
+			fmt.Printf("Error in comparison options: %v\n", err)
+			return
+		}
 
 		if out != "" && in != "" {
-			if _, err := attenuation.ProcessAttenuation(out, in, primarySortColumn); err != nil {
+			if _, err := attenuation.ProcessAttenuation(out, in, opts, statOpts, deltaOpts, networkType, primarySortColumn); err != nil {
 				fmt.Printf("Error processing attenuation: %v\n", err)
 				return
 			}
@@ -58,9 +91,12 @@ func init() {
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	// attenuationCmd.PersistentFlags().String("foo", "", "A help for foo")
-	attenuationCmd.PersistentFlags().String("outfile", "", "Outdoor siretta filename Lxxxxx.csv")
-	attenuationCmd.PersistentFlags().String("infile", "", "Indoor siretta filename Lxxxxx.csv")
+	attenuationCmd.PersistentFlags().String("outfile", "", "Outdoor siretta filename Lxxxxx.csv, directory or glob, - for stdin")
+	attenuationCmd.PersistentFlags().String("infile", "", "Indoor siretta filename Lxxxxx.csv, directory or glob, - for stdin")
 	attenuationCmd.PersistentFlags().String("primarySortColumn", "", "primary Sort Column: BAND, MNO. Default POWER")
+	addStatFlags(attenuationCmd)
+	addDeltaFlags(attenuationCmd)
+	attenuationCmd.PersistentFlags().String("network", "", "network type compared: 2G, 3G, 4G or 5G. Default all types present")
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// attenuationCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
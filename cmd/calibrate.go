@@ -0,0 +1,56 @@
+/*
+ * Copyright © 2023 LICHENS http://www.lichens.io
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/lichensio/slichens/pkg/calibrate"
+	"github.com/lichensio/slichens/pkg/lichens"
+
+	"github.com/spf13/cobra"
+)
+
+// calibrateCmd represents the calibrate command
+var calibrateCmd = &cobra.Command{
+	Use:   "calibrate",
+	Short: "Derive the calibration of a GRAPHYTE against a reference unit",
+	Long: `Derive the level offset of a GRAPHYTE against a reference unit from two surveys recorded side by side at one spot.
+The offset per band and for the whole device is printed together with the calibration entry to add to the config file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		reference, _ := cmd.Flags().GetString("reference")
+		device, _ := cmd.Flags().GetString("device")
+		minSamples, _ := cmd.Flags().GetInt("min-samples")
+
+		if reference == "" || device == "" {
+			fmt.Println("reference and device survey files required")
+			return
+		}
+		if reference == lichens.StdinName && device == lichens.StdinName {
+			fmt.Println("Only one of reference and device can be read from stdin")
+			return
+		}
+		opts, err := parseOptions()
+		if err != nil {
+			fmt.Println("Error in reader options:", err)
+			return
+		}
+		if _, err := calibrate.ProcessCalibrate(reference, device, opts, minSamples); err != nil {
+			fmt.Println("calibrate.ProcessCalibrate error:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(calibrateCmd)
+
+	calibrateCmd.PersistentFlags().String("reference", "", "reference unit siretta filename Lxxxxx.csv, directory or glob, - for stdin")
+	calibrateCmd.PersistentFlags().String("device", "", "calibrated unit siretta filename Lxxxxx.csv, directory or glob, - for stdin")
+	calibrateCmd.PersistentFlags().Int("min-samples", lichens.MinimumSampleCount, "minimum number of samples of a cell on each unit")
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright © 2023 LICHENS http://www.lichens.io
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/lichensio/slichens/pkg/cdf"
+	"github.com/lichensio/slichens/pkg/lichens"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// cdfCmd represents the cdf command
+var cdfCmd = &cobra.Command{
+	Use:   "cdf",
+	Short: "Percentiles and empirical CDF of a siretta survey metric",
+	Long: `Percentiles (P5 to P95) and empirical CDF of a metric per cell, band or operator. A coverage guarantee
+such as "RSRP ≥ -105 dBm 95 % of the time" holds when P5 is at least -105; --threshold prints the share of samples
+at or above a level. --output exports the distributions as JSON or CSV for plotting.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filename, _ := cmd.Flags().GetString("filename")
+		if filename == "" {
+			fmt.Println("survey file name required")
+			return
+		}
+		opts, err := parseOptions()
+		if err != nil {
+			fmt.Println("Error in reader options:", err)
+			return
+		}
+
+		var cdfOpts cdf.Options
+		cdfOpts.Metric, _ = cmd.Flags().GetString("metric")
+		cdfOpts.Format, _ = cmd.Flags().GetString("format")
+		cdfOpts.Output, _ = cmd.Flags().GetString("output")
+		group, _ := cmd.Flags().GetString("group")
+		if cdfOpts.Group, err = lichens.ParseGroupLevel(group); err != nil {
+			fmt.Println("Error in group:", err)
+			return
+		}
+		network, _ := cmd.Flags().GetString("network")
+		if cdfOpts.NetworkType, err = networkFlag(network); err != nil {
+			fmt.Println("Error in network:", err)
+			return
+		}
+		if cmd.Flags().Changed("threshold") {
+			threshold, _ := cmd.Flags().GetFloat64("threshold")
+			cdfOpts.Threshold = &threshold
+		}
+
+		if _, err := cdf.ProcessCDF(filename, opts, cdfOpts); err != nil {
+			fmt.Fprintln(os.Stderr, "cdf.ProcessCDF error:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cdfCmd)
+
+	cdfCmd.PersistentFlags().String("filename", "", "siretta filename Lxxxxx.csv, directory or glob, - for stdin")
+	cdfCmd.PersistentFlags().String("metric", "RSRP", "metric: DBM, RSSI, RSCP, ECIO, RSRP, RSRQ or SINR")
+	cdfCmd.PersistentFlags().String("group", "cell", "distribution per cell, band or operator")
+	cdfCmd.PersistentFlags().String("network", "", "network type: 2G, 3G, 4G or 5G. Default all types present")
+	cdfCmd.PersistentFlags().Float64("threshold", 0, "level whose coverage is printed, e.g. -105")
+	cdfCmd.PersistentFlags().String("format", "json", "export format: json or csv")
+	cdfCmd.PersistentFlags().String("output", "", "export file, - for stdout")
+}
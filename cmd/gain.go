@@ -12,6 +12,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/lichensio/slichens/pkg/gain"
+	"github.com/lichensio/slichens/pkg/lichens"
 
 	"github.com/spf13/cobra"
 )
@@ -25,8 +26,36 @@ var gainCmd = &cobra.Command{
 		out, _ := cmd.Flags().GetString("indoor")
 		in, _ := cmd.Flags().GetString("mbooster")
 		primarySortColumn, _ := cmd.Flags().GetString("primarySortColumn")
+		network, _ := cmd.Flags().GetString("network")
+		networkType, err := networkFlag(network)
+		if err != nil {
+			fmt.Println("Error in network:", err)
+			return
+		}
+		if out == lichens.StdinName && in == lichens.StdinName {
+			fmt.Println("Only one of indoor and mbooster can be read from stdin")
+			return
+		}
+		opts, err := parseOptions()
+		if err != nil {
+			fmt.Println("Error in reader options:", err)
+			return
+		}
+		statOpts, err := statOptions(cmd)
+		if err != nil {
+			fmt.Println("Error in statistics options:", err)
+			return
+		}
+		deltaOpts, err := deltaOptions(cmd)
+		if err != nil {
+			fmt.Println("Error in comparison options:", err)
+			return
+		}
 		if out != "" && in != "" {
-			gain.ProcessGain(out, in, primarySortColumn)
+			if _, err := gain.ProcessGain(out, in, opts, statOpts, deltaOpts, networkType, primarySortColumn); err != nil {
+				fmt.Printf("Error processing gain: %v\n", err)
+				return
+			}
 		} else {
 			fmt.Println("survey files name required")
 		}
@@ -41,9 +70,12 @@ func init() {
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	// gainCmd.PersistentFlags().String("foo", "", "A help for foo")
-	gainCmd.PersistentFlags().String("indoor", "", "Indoor siretta filename Lxxxxx.csv")
-	gainCmd.PersistentFlags().String("mbooster", "", "Improved Indoor siretta filename Lxxxxx.csv")
+	gainCmd.PersistentFlags().String("indoor", "", "Indoor siretta filename Lxxxxx.csv, directory or glob, - for stdin")
+	gainCmd.PersistentFlags().String("mbooster", "", "Improved Indoor siretta filename Lxxxxx.csv, directory or glob, - for stdin")
 	gainCmd.PersistentFlags().String("primarySortColumn", "", "primary Sort Column: BAND, MNO. Default POWER")
+	addStatFlags(gainCmd)
+	addDeltaFlags(gainCmd)
+	gainCmd.PersistentFlags().String("network", "", "network type compared: 2G, 3G, 4G or 5G. Default all types present")
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// gainCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
@@ -0,0 +1,77 @@
+/*
+ * Copyright © 2023 LICHENS http://www.lichens.io
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/lichensio/slichens/pkg/lichens"
+	"github.com/lichensio/slichens/pkg/merge"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "Merge several siretta surveys of the same location",
+	Long: `Merge several siretta surveys of the same location, e.g. when the GRAPHYTE was stopped and restarted
+on the spot. The rounds are renumbered in file creation order and the merged survey is written as a siretta csv file.
+Surveys from different devices, firmwares or survey types are refused unless --force is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filename, _ := cmd.Flags().GetString("filename")
+		output, _ := cmd.Flags().GetString("output")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if filename == "" || output == "" {
+			fmt.Println("survey file names and output file required")
+			return
+		}
+		opts, err := parseOptions()
+		if err != nil {
+			fmt.Println("Error in reader options:", err)
+			return
+		}
+
+		mergeOpts := lichens.MergeOptions{Mode: lichens.MergeRefuse}
+		if force {
+			mergeOpts.Mode = lichens.MergeWarn
+		}
+		merged, conflicts, err := merge.ProcessMerge(filename, output, opts, mergeOpts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "merge.ProcessMerge error:", err)
+			return
+		}
+		// Keep stdout for the survey itself when it is written there.
+		report := os.Stdout
+		if output == lichens.StdinName {
+			report = os.Stderr
+		}
+		for _, conflict := range conflicts {
+			fmt.Fprintln(report, "Warning:", conflict)
+		}
+		for _, source := range merged.Sources {
+			fmt.Fprintf(report, "%s: rounds %d-%d\n", source.Name, source.FirstRound, source.LastRound)
+		}
+		if report == os.Stdout {
+			lichens.PrintParseWarnings(merged.Warnings)
+		} else if len(merged.Warnings) > 0 {
+			fmt.Fprintf(report, "%d malformed rows skipped\n", len(merged.Warnings))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+
+	mergeCmd.PersistentFlags().String("filename", "", "siretta filenames to merge: directory or glob")
+	mergeCmd.PersistentFlags().String("output", "", "merged siretta csv file, - for stdout")
+	mergeCmd.PersistentFlags().Bool("force", false, "merge surveys of different devices, firmwares or survey types with a warning")
+}
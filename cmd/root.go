@@ -11,10 +11,14 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/lichensio/slichens/pkg/lichens"
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var cfgFile string
@@ -50,12 +54,142 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.lichens.yaml)")
+	rootCmd.PersistentFlags().Bool("strict", false, "abort on the first malformed survey row instead of skipping it")
+	viper.BindPFlag("strict", rootCmd.PersistentFlags().Lookup("strict"))
+	rootCmd.PersistentFlags().String("timezone", "", "IANA timezone of the survey timestamps, e.g. Europe/Paris. Default UTC")
+	viper.BindPFlag("timezone", rootCmd.PersistentFlags().Lookup("timezone"))
+	rootCmd.PersistentFlags().String("date-order", "auto", "survey timestamp date order: auto, day or month")
+	viper.BindPFlag("date_order", rootCmd.PersistentFlags().Lookup("date-order"))
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
+// parseOptions builds the survey reader options from the flags and config.
+func parseOptions() (lichens.ParseOptions, error) {
+	opts := lichens.ParseOptions{Mode: lichens.ParseLenient}
+	if viper.GetBool("strict") {
+		opts.Mode = lichens.ParseStrict
+	}
+
+	order, err := lichens.ParseDateOrder(viper.GetString("date_order"))
+	if err != nil {
+		return opts, err
+	}
+	opts.DateOrder = order
+
+	if tz := viper.GetString("timezone"); tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			return opts, fmt.Errorf("invalid timezone: %w", err)
+		}
+		opts.Location = location
+	}
+
+	profiles, err := calibrationProfiles()
+	if err != nil {
+		return opts, err
+	}
+	opts.Calibration = profiles
+	return opts, nil
+}
+
+// calibrationProfiles reads the device profiles of the config file:
+//
+//	calibration:
+//	  "351626102376784":
+//	    offset: 1.5
+//	    bands:
+//	      800: 2.0
+func calibrationProfiles() (lichens.CalibrationProfiles, error) {
+	var config map[string]struct {
+		Offset float64
+		Bands  map[string]float64
+	}
+	if err := viper.UnmarshalKey("calibration", &config); err != nil {
+		return nil, fmt.Errorf("invalid calibration: %w", err)
+	}
+
+	profiles := make(lichens.CalibrationProfiles, len(config))
+	for imei, profile := range config {
+		calibration := lichens.Calibration{Offset: profile.Offset}
+		if len(profile.Bands) > 0 {
+			calibration.Bands = make(map[int]float64, len(profile.Bands))
+		}
+		for band, offset := range profile.Bands {
+			frequency, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(band), " MHz"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid calibration band %q for %s: %w", band, imei, err)
+			}
+			calibration.Bands[frequency] = offset
+		}
+		profiles[lichens.NormaliseIMEI(imei)] = calibration
+	}
+	return profiles, nil
+}
+
+// addStatFlags adds the flags read by statOptions to a command.
+func addStatFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("average", "db", "averaging of the levels: db (arithmetic mean of dB values), linear (mW power average) or median")
+	cmd.PersistentFlags().Float64("confidence", lichens.DefaultConfidence, "confidence level of the ± intervals of the means")
+	cmd.PersistentFlags().Int("bootstrap", 0, "bootstrap resamples for the ± intervals, 0 for the t interval")
+	cmd.PersistentFlags().Float64("max-ci", 3, "flag means whose ± interval is wider than this many dB, 0 for none")
+}
+
+// statOptions builds the statistics options from the flags of cmd.
+func statOptions(cmd *cobra.Command) (lichens.StatOptions, error) {
+	average, _ := cmd.Flags().GetString("average")
+	mode, err := lichens.ParseAverageMode(average)
+	if err != nil {
+		return lichens.StatOptions{}, err
+	}
+	opts := lichens.StatOptions{Average: mode}
+	opts.Confidence, _ = cmd.Flags().GetFloat64("confidence")
+	if opts.Confidence <= 0 || opts.Confidence >= 1 {
+		return opts, fmt.Errorf("confidence %g not between 0 and 1", opts.Confidence)
+	}
+	opts.Bootstrap, _ = cmd.Flags().GetInt("bootstrap")
+	opts.MaxCI, _ = cmd.Flags().GetFloat64("max-ci")
+	return opts, nil
+}
+
+// addDeltaFlags adds the flags read by deltaOptions to a command.
+func addDeltaFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().Float64("alpha", lichens.DefaultAlpha, "significance level of the comparison of the cells")
+	cmd.PersistentFlags().String("test", "welch", "test deciding whether a cell differs: welch (t-test) or mann-whitney")
+	cmd.PersistentFlags().Int("resamples", lichens.DefaultDeltaResamples, "bootstrap resamples of the delta intervals")
+}
+
+// deltaOptions builds the comparison options from the flags of cmd.
+func deltaOptions(cmd *cobra.Command) (lichens.DeltaOptions, error) {
+	test, _ := cmd.Flags().GetString("test")
+	deltaTest, err := lichens.ParseDeltaTest(test)
+	if err != nil {
+		return lichens.DeltaOptions{}, err
+	}
+	opts := lichens.DeltaOptions{Test: deltaTest}
+	opts.Alpha, _ = cmd.Flags().GetFloat64("alpha")
+	if opts.Alpha <= 0 || opts.Alpha >= 1 {
+		return opts, fmt.Errorf("alpha %g not between 0 and 1", opts.Alpha)
+	}
+	opts.Resamples, _ = cmd.Flags().GetInt("resamples")
+	return opts, nil
+}
+
+// networkFlag normalises a --network value; an empty value selects every
+// network type present in the surveys.
+func networkFlag(network string) (string, error) {
+	if network == "" {
+		return "", nil
+	}
+	networkType, err := lichens.ParseGSMAType(network)
+	if err != nil {
+		return "", err
+	}
+	return networkType.String(), nil
+}
+
 func initConfig() {
 	if cfgFile != "" {
 		// Use config file from the flag.
@@ -26,31 +26,45 @@ var surveyCmd = &cobra.Command{
 			return
 		}
 
-		fmt.Println(filename)
-		summaryOut, errorPS := survey.ProcessSurvey(filename, false, false, false)
-		if errorPS != nil {
-			fmt.Println("survey.ProcessSurvey error:", errorPS)
+		opts, err := parseOptions()
+		if err != nil {
+			fmt.Println("Error in reader options:", err)
 			return
 		}
 
-		err1 := lichens.TablePrintALL("Survey", summaryOut, primarySortColumn)
-		err2 := lichens.TablePrintStats("Survey", false, summaryOut, "2G", primarySortColumn)
-		err3 := lichens.TablePrintStats("Survey", false, summaryOut, "3G", primarySortColumn)
-		err4 := lichens.TablePrintStats("Survey", false, summaryOut, "4G", primarySortColumn)
+		statOpts, err := statOptions(cmd)
+		if err != nil {
+			fmt.Println("Error in statistics options:", err)
+			return
+		}
 
-		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
-			if err1 != nil {
-				fmt.Println("TablePrintALL error:", err1)
-			}
-			if err2 != nil {
-				fmt.Println("TablePrintStats (2G) error:", err2)
+		workers, _ := cmd.Flags().GetInt("workers")
+		summaries, errorPS := survey.ProcessSurveys(filename, opts, statOpts, workers, false)
+		if errorPS != nil {
+			fmt.Println("survey.ProcessSurveys error:", errorPS)
+		}
+
+		for _, name := range lichens.SortedFilenames(summaries) {
+			summaryOut := summaries[name]
+			fmt.Println(name)
+
+			if err := lichens.TablePrintALL("Survey", summaryOut, primarySortColumn); err != nil {
+				fmt.Println("TablePrintALL error:", err)
 			}
-			if err3 != nil {
-				fmt.Println("TablePrintStats (3G) error:", err3)
+
+			// Only the network types present in the survey get a stats table.
+			networkTypes := lichens.NetworkTypes(summaryOut.Stat)
+			for _, networkType := range networkTypes {
+				if err := lichens.TablePrintStats("Survey", false, summaryOut, networkType, primarySortColumn); err != nil {
+					fmt.Printf("TablePrintStats (%s) error: %v\n", networkType, err)
+				}
 			}
-			if err4 != nil {
-				fmt.Println("TablePrintStats (4G) error:", err4)
+			if err := lichens.TablePrintOperatorOverview("Survey", summaryOut); err != nil {
+				fmt.Println("TablePrintOperatorOverview error:", err)
 			}
+
+			lichens.PrintUnsupported(summaryOut.Unsupported)
+			lichens.PrintParseWarnings(summaryOut.Warnings)
 		}
 	},
 }
@@ -62,7 +76,9 @@ func init() {
 
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
-	surveyCmd.PersistentFlags().String("filename", "", "siretta filename Lxxxxx.csv")
+	surveyCmd.PersistentFlags().String("filename", "", "siretta filename Lxxxxx.csv, directory or glob, - for stdin")
+	addStatFlags(surveyCmd)
+	surveyCmd.PersistentFlags().Int("workers", 0, "number of files parsed concurrently. Default one per CPU")
 	surveyCmd.PersistentFlags().String("primarySortColumn", "", "primary Sort Column: BAND, MNO. Default POWER")
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
@@ -0,0 +1,66 @@
+/*
+ * Copyright © 2023 LICHENS http://www.lichens.io
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/lichensio/slichens/pkg/lichens"
+	"github.com/lichensio/slichens/pkg/validate"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check that siretta surveys are usable",
+	Long: `Check that siretta surveys are usable before billing: header block, timestamps, Index repeated within a round,
+RSRP and RSRQ out of range, sentinel readings, number of rounds and session length. Each file gets a quality score
+out of 100 with the findings; the command exits with status 1 when a file fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filename, _ := cmd.Flags().GetString("filename")
+		if filename == "" {
+			fmt.Println("survey file name required")
+			os.Exit(1)
+		}
+		opts, err := parseOptions()
+		if err != nil {
+			fmt.Println("Error in reader options:", err)
+			os.Exit(1)
+		}
+
+		validationOpts := lichens.DefaultValidationOptions()
+		validationOpts.MinRounds, _ = cmd.Flags().GetInt("min-rounds")
+		validationOpts.MinDuration, _ = cmd.Flags().GetDuration("min-duration")
+		validationOpts.PassScore, _ = cmd.Flags().GetInt("pass-score")
+
+		reports, err := validate.ProcessValidate(filename, opts, validationOpts)
+		if err != nil {
+			fmt.Println("validate.ProcessValidate error:", err)
+			os.Exit(1)
+		}
+		for _, report := range reports {
+			if !report.Passed {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	defaults := lichens.DefaultValidationOptions()
+	validateCmd.PersistentFlags().String("filename", "", "siretta filename Lxxxxx.csv, directory or glob, - for stdin")
+	validateCmd.PersistentFlags().Int("min-rounds", defaults.MinRounds, "minimum number of survey rounds")
+	validateCmd.PersistentFlags().Duration("min-duration", defaults.MinDuration, "minimum session length")
+	validateCmd.PersistentFlags().Int("pass-score", defaults.PassScore, "minimum quality score")
+}
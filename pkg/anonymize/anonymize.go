@@ -0,0 +1,91 @@
+package anonymize
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/lichensio/slichens/pkg/lichens"
+)
+
+/*
+ * Copyright © 2023 LICHENS http://www.lichens.io
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// ProcessAnonymize anonymises every survey matched by pattern with one
+// Anonymizer, so the whole batch shares its time shift and cell pseudonyms,
+// and writes them as Siretta CSV files in outputDir. It returns the names of
+// the files written; files that cannot be read are reported in the error and
+// the others are still written. The files are named from the shifted start
+// time, as the GRAPHYTE does, since the original names give it away.
+func ProcessAnonymize(pattern, outputDir string, opts lichens.ParseOptions, anonymizeOpts lichens.AnonymizeOptions) ([]string, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("Please provide a siretta survey file name, L____.CSV")
+	}
+	if outputDir == "" {
+		return nil, fmt.Errorf("Please provide an output directory")
+	}
+
+	surveys, readErr := lichens.ReadSurveyDirWithOptions(pattern, 0, opts)
+	if readErr != nil {
+		readErr = fmt.Errorf("Error reading CSV: %w", readErr)
+		if len(surveys) == 0 {
+			return nil, readErr
+		}
+	}
+	anonymizer, err := lichens.NewAnonymizer(anonymizeOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	seen := make(map[string]bool)
+	for _, name := range lichens.SortedFilenames(surveys) {
+		survey := anonymizer.Anonymize(surveys[name])
+		outputName := filepath.Join(outputDir, outputFilename(survey.Filename, seen))
+		if err := writeSurvey(outputName, survey); err != nil {
+			return written, err
+		}
+		written = append(written, outputName)
+	}
+	return written, readErr
+}
+
+// outputFilename is the file name of an anonymised survey, with the upper
+// case extension of the GRAPHYTE. Surveys started in the same minute, or
+// without a start time, are numbered.
+func outputFilename(name string, seen map[string]bool) string {
+	base := strings.TrimSuffix(name, path.Ext(name))
+	if base == "" {
+		base = "survey"
+	}
+	candidate := base + ".CSV"
+	for i := 2; seen[candidate]; i++ {
+		candidate = fmt.Sprintf("%s_%d.CSV", base, i)
+	}
+	seen[candidate] = true
+	return candidate
+}
+
+func writeSurvey(filename string, survey lichens.SurveyInfo) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := lichens.WriteSirettaCSV(file, survey); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
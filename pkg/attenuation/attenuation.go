@@ -6,7 +6,9 @@ import (
 	"github.com/lichensio/slichens/pkg/survey"
 )
 
-func GenerateDeltaStats(set1, set2 lichens.SurveySummary, DeltaType lichens.DeltaType) (lichens.SurveyDeltaStatsSummary, lichens.SurveySummary, lichens.SurveySummary, error) {
+// GenerateDeltaStats compares the cells seen in both summaries, set2 against
+// set1, and returns the cells seen in only one of them.
+func GenerateDeltaStats(set1, set2 lichens.SurveySummary, DeltaType lichens.DeltaType, deltaOpts lichens.DeltaOptions) (lichens.SurveyDeltaStatsSummary, lichens.SurveySummary, lichens.SurveySummary, error) {
 	set1StatsMap := set1.Stat
 	set2StatsMap := set2.Stat
 	set1KeysSet := lichens.CreateKeySet(set1StatsMap)
@@ -14,6 +16,7 @@ func GenerateDeltaStats(set1, set2 lichens.SurveySummary, DeltaType lichens.Delt
 	common, uniqueToSet1, uniqueToSet2 := lichens.CompareKeySets(set1KeysSet, set2KeysSet)
 	// deltas := make(map[SurveyKey]SurveyDeltaStats)
 	survey := lichens.NewSurveyDeltaSummary(set1.SurveyType, DeltaType)
+	survey.Options = deltaOpts
 	// survey.DeltaType = DeltaType
 	// survey.SurveyType = set1.SurveyType
 	// survey := SurveyDeltaStatsSummary{set1.SurveyType, deltas, DeltaType, 0.0, 0.0}
@@ -23,17 +26,21 @@ func GenerateDeltaStats(set1, set2 lichens.SurveySummary, DeltaType lichens.Delt
 		if !exists {
 			deltaStatsForThisKey = make(lichens.SurveyDeltaStats)
 		} // get the SurveyDeltaStats value (it's a copy)
-		deltaStatsForThisKey.CalculateDelta(set1.Stat[key], set2.Stat[key]) // modify the copy
+		if err := deltaStatsForThisKey.CalculateDelta(set1.Stat[key], set2.Stat[key], deltaOpts); err != nil { // modify the copy
+			return lichens.SurveyDeltaStatsSummary{}, lichens.SurveySummary{}, lichens.SurveySummary{}, fmt.Errorf("%s %s band %d cell %d: %w", key.NetworkType, key.NetName, key.Band, key.CellID, err)
+		}
 		survey.Set(key, deltaStatsForThisKey)
 		// survey.DeltaStats[key] = deltaStatsForThisKey                       // put the modified copy back into the map
 	}
 
 	surveySet1 := lichens.NewSurveyStatsSummary(set1.SurveyType)
+	surveySet1.Options = set1.Options
 	for key, _ := range uniqueToSet1 {
 		surveySet1.Set(key, set1StatsMap[key]) // put the modified copy back into the map
 	}
 
 	surveySet2 := lichens.NewSurveyStatsSummary(set2.SurveyType)
+	surveySet2.Options = set2.Options
 	for key, _ := range uniqueToSet2 {
 		surveySet2.Set(key, set2StatsMap[key]) // put the modified copy back into the map
 	}
@@ -41,17 +48,17 @@ func GenerateDeltaStats(set1, set2 lichens.SurveySummary, DeltaType lichens.Delt
 
 }
 
-func ProcessAttenuation(filename1, filename2 string, primarySortColumn string) (lichens.SurveyDeltaStatsSummary, error) {
+func ProcessAttenuation(filename1, filename2 string, opts lichens.ParseOptions, statOpts lichens.StatOptions, deltaOpts lichens.DeltaOptions, networkType, primarySortColumn string) (lichens.SurveyDeltaStatsSummary, error) {
 	if filename1 == "" || filename2 == "" {
 		return lichens.SurveyDeltaStatsSummary{}, fmt.Errorf("Please provide a siretta survey file name 1 & 2, L____.CSV")
 	}
 
-	summaryOutdoor, errOutdoor := survey.ProcessSurvey(filename1, false, false, false)
+	summaryOutdoor, errOutdoor := survey.ProcessSurvey(filename1, opts, statOpts, false, false, false)
 	if errOutdoor != nil {
 		return lichens.SurveyDeltaStatsSummary{}, fmt.Errorf("Error processing outdoor survey: %v", errOutdoor)
 	}
 
-	summaryIndoor, errIndoor := survey.ProcessSurvey(filename2, false, false, false)
+	summaryIndoor, errIndoor := survey.ProcessSurvey(filename2, opts, statOpts, false, false, false)
 	if errIndoor != nil {
 		return lichens.SurveyDeltaStatsSummary{}, fmt.Errorf("Error processing indoor survey: %v", errIndoor)
 	}
@@ -65,13 +72,20 @@ func ProcessAttenuation(filename1, filename2 string, primarySortColumn string) (
 		return lichens.SurveyDeltaStatsSummary{}, err
 	}
 
-	common, uniqueToSetOutdoor, uniqueToSetIndoor, errDelta := GenerateDeltaStats(summaryOutdoor, summaryIndoor, lichens.IndoorOutdoor)
+	common, uniqueToSetOutdoor, uniqueToSetIndoor, errDelta := GenerateDeltaStats(summaryOutdoor, summaryIndoor, lichens.IndoorOutdoor, deltaOpts)
 	if errDelta != nil {
 		return lichens.SurveyDeltaStatsSummary{}, fmt.Errorf("Error generating delta stats: %v", errDelta)
 	}
 
-	if err := lichens.PrintDeltaStatsTable("Attenuation between Outdoor and Indoor", false, common, "4G", primarySortColumn); err != nil {
-		return lichens.SurveyDeltaStatsSummary{}, err
+	// Without a network type, every type common to both surveys is reported.
+	networkTypes := []string{networkType}
+	if networkType == "" {
+		networkTypes = lichens.NetworkTypes(common.DeltaStats)
+	}
+	for _, networkType := range networkTypes {
+		if err := lichens.PrintDeltaStatsTable("Attenuation between Outdoor and Indoor", false, common, networkType, primarySortColumn); err != nil {
+			return lichens.SurveyDeltaStatsSummary{}, err
+		}
 	}
 
 	if err := lichens.TablePrintALL("Survey unique to Outdoor", uniqueToSetOutdoor, primarySortColumn); err != nil {
@@ -82,5 +96,6 @@ func ProcessAttenuation(filename1, filename2 string, primarySortColumn string) (
 		return lichens.SurveyDeltaStatsSummary{}, err
 	}
 
+	lichens.PrintParseWarnings(append(summaryOutdoor.Warnings, summaryIndoor.Warnings...))
 	return common, nil
 }
@@ -0,0 +1,63 @@
+package calibrate
+
+import (
+	"fmt"
+
+	"github.com/lichensio/slichens/pkg/lichens"
+)
+
+/*
+ * Copyright © 2023 LICHENS http://www.lichens.io
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// ProcessCalibrate derives the calibration of the device that recorded the
+// device survey against the reference survey, both taken side by side at one
+// spot. The raw levels are compared: configured profiles are not applied. It
+// prints the offsets per band and the config entry of the device.
+func ProcessCalibrate(reference, device string, opts lichens.ParseOptions, minSamples int) (lichens.Calibration, error) {
+	opts.Calibration = nil
+	referenceSurvey, err := readSurvey(reference, opts)
+	if err != nil {
+		return lichens.Calibration{}, err
+	}
+	deviceSurvey, err := readSurvey(device, opts)
+	if err != nil {
+		return lichens.Calibration{}, err
+	}
+
+	calibration, results, err := lichens.DeriveCalibration(referenceSurvey, deviceSurvey, minSamples)
+	if err != nil {
+		return calibration, err
+	}
+
+	imei := lichens.NormaliseIMEI(deviceSurvey.IMEINumber)
+	fmt.Println("Reference:", lichens.NormaliseIMEI(referenceSurvey.IMEINumber))
+	fmt.Println("Device:", imei)
+	lichens.TablePrintCalibration("Calibration", results)
+	fmt.Println("calibration:")
+	fmt.Printf("  %q:\n", imei)
+	fmt.Printf("    offset: %.2f\n", calibration.Offset)
+	if len(results) > 1 {
+		fmt.Println("    bands:")
+		for _, result := range results[1:] {
+			fmt.Printf("      %d: %.2f\n", result.Band, result.Offset)
+		}
+	}
+	return calibration, nil
+}
+
+// readSurvey reads the files matched by pattern as one survey.
+func readSurvey(pattern string, opts lichens.ParseOptions) (lichens.SurveyInfo, error) {
+	surveys, err := lichens.ReadSurveyDirWithOptions(pattern, 0, opts)
+	if err != nil {
+		return lichens.SurveyInfo{}, fmt.Errorf("Error reading CSV: %w", err)
+	}
+	survey, _, err := lichens.Merge(surveys, lichens.MergeOptions{Mode: lichens.MergeRefuse})
+	return survey, err
+}
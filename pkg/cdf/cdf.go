@@ -0,0 +1,97 @@
+package cdf
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lichensio/slichens/pkg/lichens"
+)
+
+/*
+ * Copyright © 2023 LICHENS http://www.lichens.io
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// Options selects the distributions computed by ProcessCDF.
+type Options struct {
+	Metric      string             // "RSRP", "DBM", ...
+	Group       lichens.GroupLevel // cell, band or operator
+	NetworkType string             // "" for all
+	Threshold   *float64           // coverage threshold printed, if any
+	Format      string             // export format: json or csv
+	Output      string             // export file, - for stdout, "" for none
+}
+
+// ProcessCDF computes the percentiles and empirical CDF of a metric over the
+// files matched by pattern, merged into one survey. It prints the percentile
+// table and, with an output, exports the distributions as JSON or CSV.
+func ProcessCDF(pattern string, opts lichens.ParseOptions, cdfOpts Options) ([]lichens.ECDF, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("Please provide a siretta survey file name, L____.CSV")
+	}
+	format := strings.ToLower(cdfOpts.Format)
+	if format != "json" && format != "csv" {
+		return nil, fmt.Errorf("unknown format %q, expected json or csv", cdfOpts.Format)
+	}
+	metric := strings.ToUpper(cdfOpts.Metric)
+
+	surveys, err := lichens.ReadSurveyDirWithOptions(pattern, 0, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading CSV: %w", err)
+	}
+	survey, conflicts, err := lichens.Merge(surveys, lichens.MergeOptions{Mode: lichens.MergeWarn})
+	if err != nil {
+		return nil, err
+	}
+	// Keep stdout for the export when it is written there.
+	report := os.Stdout
+	if cdfOpts.Output == lichens.StdinName {
+		report = os.Stderr
+	}
+	for _, conflict := range conflicts {
+		fmt.Fprintln(report, "Warning, merged surveys:", conflict)
+	}
+
+	cells := survey.Surveys
+	if cdfOpts.NetworkType != "" {
+		cells = lichens.SelectSurveys(cells, lichens.SurveyKey{NetworkType: cdfOpts.NetworkType})
+	}
+	distributions := lichens.SurveyECDF(lichens.GroupSurveys(cells, cdfOpts.Group), metric)
+	if len(distributions) == 0 {
+		return nil, fmt.Errorf("no sample reports %s", metric)
+	}
+
+	if report == os.Stdout {
+		title := fmt.Sprintf("%s percentiles per %s", metric, cdfOpts.Group)
+		lichens.TablePrintPercentiles(title, distributions, cdfOpts.Threshold)
+	}
+	switch cdfOpts.Output {
+	case "":
+		return distributions, nil
+	case lichens.StdinName:
+		return distributions, export(os.Stdout, format, distributions)
+	}
+	file, err := os.Create(cdfOpts.Output)
+	if err != nil {
+		return distributions, err
+	}
+	if err := export(file, format, distributions); err != nil {
+		file.Close()
+		return distributions, err
+	}
+	return distributions, file.Close()
+}
+
+func export(w io.Writer, format string, distributions []lichens.ECDF) error {
+	if format == "csv" {
+		return lichens.WriteECDFCSV(w, distributions)
+	}
+	return lichens.WriteECDFJSON(w, distributions)
+}
@@ -7,20 +7,37 @@ import (
 	"github.com/lichensio/slichens/pkg/survey"
 )
 
-func ProcessGain(filename1, filename2 string, primarySortColumn string) (lichens.SurveyDeltaStatsSummary, error) {
+func ProcessGain(filename1, filename2 string, opts lichens.ParseOptions, statOpts lichens.StatOptions, deltaOpts lichens.DeltaOptions, networkType, primarySortColumn string) (lichens.SurveyDeltaStatsSummary, error) {
 	if filename1 == "" || filename2 == "" {
 		fmt.Println("Please provide a siretta survey file name 1 & 2, L____.CSV")
 		return lichens.SurveyDeltaStatsSummary{}, fmt.Errorf("Please provide a siretta survey file name  1 & 2, L____.CSV")
 	}
-	summaryindoor, _ := survey.ProcessSurvey(filename1, false, false, false)
-	summarybooster, _ := survey.ProcessSurvey(filename2, false, false, false)
+	summaryindoor, errIndoor := survey.ProcessSurvey(filename1, opts, statOpts, false, false, false)
+	if errIndoor != nil {
+		return lichens.SurveyDeltaStatsSummary{}, fmt.Errorf("Error processing indoor survey: %v", errIndoor)
+	}
+	summarybooster, errBooster := survey.ProcessSurvey(filename2, opts, statOpts, false, false, false)
+	if errBooster != nil {
+		return lichens.SurveyDeltaStatsSummary{}, fmt.Errorf("Error processing booster survey: %v", errBooster)
+	}
 
 	lichens.TablePrintALL("Survey Indoor", summaryindoor, primarySortColumn)
 	lichens.TablePrintALL("Survey Booster", summarybooster, primarySortColumn)
 
-	common, uniqueToSetOutdoor, uniqueToSetIndoor, _ := attenuation.GenerateDeltaStats(summaryindoor, summarybooster, lichens.IndoorBooster)
+	common, uniqueToSetOutdoor, uniqueToSetIndoor, errDelta := attenuation.GenerateDeltaStats(summaryindoor, summarybooster, lichens.IndoorBooster, deltaOpts)
+	if errDelta != nil {
+		return lichens.SurveyDeltaStatsSummary{}, fmt.Errorf("Error generating delta stats: %v", errDelta)
+	}
 	lichens.TablePrintALL("Survey unique to Indoor", uniqueToSetOutdoor, primarySortColumn)
 	lichens.TablePrintALL("Survey unique to Booster", uniqueToSetIndoor, primarySortColumn)
-	lichens.PrintDeltaStatsTable("Gain between Indoor and Booster", false, common, "4G", primarySortColumn)
+	// Without a network type, every type common to both surveys is reported.
+	networkTypes := []string{networkType}
+	if networkType == "" {
+		networkTypes = lichens.NetworkTypes(common.DeltaStats)
+	}
+	for _, networkType := range networkTypes {
+		lichens.PrintDeltaStatsTable("Gain between Indoor and Booster", false, common, networkType, primarySortColumn)
+	}
+	lichens.PrintParseWarnings(append(summaryindoor.Warnings, summarybooster.Warnings...))
 	return common, nil
 }
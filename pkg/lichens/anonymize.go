@@ -0,0 +1,223 @@
+package lichens
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IMEIMode selects what Anonymizer does with the device IMEI.
+type IMEIMode int
+
+const (
+	// IMEIDrop removes the IMEI.
+	IMEIDrop IMEIMode = iota
+	// IMEIHash replaces the IMEI by a keyed hash, so that surveys of the same
+	// device can still be told apart.
+	IMEIHash
+	// IMEIKeep leaves the IMEI unchanged.
+	IMEIKeep
+)
+
+// ParseIMEIMode converts "drop", "hash" or "keep" to an IMEIMode.
+func ParseIMEIMode(s string) (IMEIMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "drop":
+		return IMEIDrop, nil
+	case "hash":
+		return IMEIHash, nil
+	case "keep":
+		return IMEIKeep, nil
+	default:
+		return IMEIDrop, fmt.Errorf("unknown IMEI mode %q, expected drop, hash or keep", s)
+	}
+}
+
+// AnonymizeOptions configures an Anonymizer.
+type AnonymizeOptions struct {
+	IMEI IMEIMode
+	// Key seeds the IMEI hash and the cell pseudonyms. Runs with the same key
+	// give the same pseudonyms, unless two values of a run hash to the same
+	// one; a random key is drawn when it is empty.
+	Key []byte
+	// Shift is added to every timestamp when FixedShift is set, zero meaning
+	// no shift. Otherwise a random shift of up to MaxShift in either direction
+	// is drawn.
+	Shift      time.Duration
+	FixedShift bool
+	MaxShift   time.Duration
+	// Cells replaces CellID, LAC/TAC and PCI by pseudonyms.
+	Cells bool
+}
+
+// Anonymizer removes the identifying data of surveys before they leave the
+// company. One Anonymizer applies the same time shift and cell pseudonyms to
+// every survey it is given, so the attenuation and gain of a batch are
+// unchanged. GPS positions and unknown columns are always dropped.
+type Anonymizer struct {
+	opts AnonymizeOptions
+
+	mu         sync.Mutex
+	pseudonyms map[string]map[int]int  // by domain, original value to pseudonym
+	used       map[string]map[int]bool // by domain, pseudonyms given
+}
+
+// NewAnonymizer draws the key and shift left unset in opts.
+func NewAnonymizer(opts AnonymizeOptions) (*Anonymizer, error) {
+	if len(opts.Key) == 0 {
+		opts.Key = make([]byte, 32)
+		if _, err := rand.Read(opts.Key); err != nil {
+			return nil, err
+		}
+	}
+	if !opts.FixedShift && opts.MaxShift > 0 {
+		n, err := rand.Int(rand.Reader, big.NewInt(2*int64(opts.MaxShift/time.Second)+1))
+		if err != nil {
+			return nil, err
+		}
+		opts.Shift = time.Duration(n.Int64())*time.Second - opts.MaxShift.Truncate(time.Second)
+	}
+	return &Anonymizer{
+		opts:       opts,
+		pseudonyms: make(map[string]map[int]int),
+		used:       make(map[string]map[int]bool),
+	}, nil
+}
+
+// GraphyteFilename returns the name the GRAPHYTE gives a survey started at t:
+// L, the month in hex, the day, the hour and the minute, as in L3240918.csv.
+func GraphyteFilename(t time.Time) string {
+	return fmt.Sprintf("L%X%02d%02d%02d.csv", int(t.Month()), t.Day(), t.Hour(), t.Minute())
+}
+
+// Shift returns the time shift applied to the surveys.
+func (a *Anonymizer) Shift() time.Duration {
+	return a.opts.Shift
+}
+
+// Anonymize returns an anonymised copy of s. The survey map is rebuilt since
+// the cell pseudonyms change the keys.
+func (a *Anonymizer) Anonymize(s SurveyInfo) SurveyInfo {
+	out := s
+	switch a.opts.IMEI {
+	case IMEIDrop:
+		out.IMEINumber = ""
+	case IMEIHash:
+		imei := strings.TrimPrefix(strings.TrimSpace(s.IMEINumber), "'")
+		if imei != "" {
+			out.IMEINumber = hex.EncodeToString(a.sum("imei", imei)[:8])
+		}
+	}
+	out.FileCreated = s.FileCreated.Add(a.opts.Shift)
+	// A GRAPHYTE filename encodes the start time of the survey.
+	out.Filename = ""
+	if !out.FileCreated.IsZero() {
+		location := s.Location
+		if location == nil {
+			location = out.FileCreated.Location()
+		}
+		out.Filename = GraphyteFilename(out.FileCreated.In(location))
+	}
+
+	// The cells are taken in key order so that the pseudonyms given on a
+	// collision do not depend on the map order.
+	keys := make([]SurveyKey, 0, len(s.Surveys))
+	for key := range s.Surveys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keyLess(keys[i], keys[j]) })
+
+	out.Surveys = make(SurveyMap, len(s.Surveys))
+	for _, key := range keys {
+		for _, data := range s.Surveys[key] {
+			data = a.anonymizeData(data)
+			key := cellKey(data)
+			out.Surveys[key] = append(out.Surveys[key], data)
+		}
+	}
+	out.Rounds = BuildRounds(out.Surveys)
+	out.Warnings = nil
+	return out
+}
+
+func (a *Anonymizer) anonymizeData(data SurveyData) SurveyData {
+	data.Timestamp = data.Timestamp.Add(a.opts.Shift)
+	data.Latitude, data.Longitude = OptionalFloat{}, OptionalFloat{}
+	data.Extra = nil
+	if !a.opts.Cells {
+		return data
+	}
+
+	// Unresolved neighbours have CellID -PCI and keep their sign.
+	cellID := a.pseudonym("cell:"+data.Network, data.CellID, 1<<28)
+	if data.CellID < 0 {
+		cellID = -cellID
+	}
+	data.CellID = cellID
+	data.LACTAC = a.pseudonym("area:"+data.Network, data.LACTAC, 1<<16)
+	switch data.Network {
+	case "4G":
+		data.PCI = a.pseudonym("pci:4G", data.PCI, 504)
+	case "5G":
+		data.PCI = a.pseudonym("pci:5G", data.PCI, 1008)
+	}
+	return data
+}
+
+// pseudonym maps value to [1, limit), keeping 0 for not reported, with a hash
+// keyed by the Anonymizer key. The mapping is one-to-one within a domain, so
+// that no two cells are merged: a pseudonym already given is rehashed, and
+// once [1, limit) is used up the next values follow it.
+func (a *Anonymizer) pseudonym(domain string, value int, limit uint64) int {
+	if value == 0 {
+		return 0
+	}
+	if value < 0 {
+		value = -value
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	pseudonyms, used := a.pseudonyms[domain], a.used[domain]
+	if pseudonyms == nil {
+		pseudonyms, used = make(map[int]int), make(map[int]bool)
+		a.pseudonyms[domain], a.used[domain] = pseudonyms, used
+	}
+	if pseudonym, ok := pseudonyms[value]; ok {
+		return pseudonym
+	}
+
+	pseudonym := len(used) + 1
+	if uint64(len(used)) < limit-1 {
+		for probe := 0; ; probe++ {
+			input := strconv.Itoa(value)
+			if probe > 0 {
+				input += "#" + strconv.Itoa(probe)
+			}
+			pseudonym = int(binary.BigEndian.Uint64(a.sum(domain, input))%(limit-1)) + 1
+			if !used[pseudonym] {
+				break
+			}
+		}
+	}
+	pseudonyms[value] = pseudonym
+	used[pseudonym] = true
+	return pseudonym
+}
+
+func (a *Anonymizer) sum(domain, value string) []byte {
+	mac := hmac.New(sha256.New, a.opts.Key)
+	mac.Write([]byte(domain))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,97 @@
+package lichens
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"testing"
+	"time"
+)
+
+// cellSummaries describes each cell of s by everything but its identity.
+func cellSummaries(s SurveyInfo) []string {
+	var summaries []string
+	for key, slice := range s.Surveys {
+		stats := newCensoredStats(slice.Observations("DBM"))
+		summaries = append(summaries, fmt.Sprintf("%s %s %d: n=%d mean=%.6f sd=%.6f",
+			key.NetworkType, key.NetName, key.Band, stats.Number, stats.Mean, stats.StandardDeviation))
+	}
+	sort.Strings(summaries)
+	return summaries
+}
+
+func TestAnonymizeKeepsCells(t *testing.T) {
+	file, err := os.Open("../../L3240918.CSV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	survey, err := ParseSurvey(file, ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anonymizer, err := NewAnonymizer(AnonymizeOptions{Key: []byte("test"), Shift: time.Hour, FixedShift: true, Cells: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := anonymizer.Anonymize(survey)
+
+	if out.IMEINumber != "" {
+		t.Errorf("IMEI %q kept", out.IMEINumber)
+	}
+	if want := survey.FileCreated.Add(time.Hour); !out.FileCreated.Equal(want) {
+		t.Errorf("FileCreated = %v, want %v", out.FileCreated, want)
+	}
+	if out.Filename != "L3241020.csv" {
+		t.Errorf("Filename = %q, want the shifted L3241020.csv", out.Filename)
+	}
+	if len(out.Surveys) != len(survey.Surveys) {
+		t.Fatalf("%d cells after anonymisation, want %d", len(out.Surveys), len(survey.Surveys))
+	}
+	want, got := cellSummaries(survey), cellSummaries(out)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("cell %s after anonymisation, want %s", got[i], want[i])
+		}
+	}
+	for key := range out.Surveys {
+		if _, ok := survey.Surveys[key]; ok {
+			t.Errorf("cell %v kept its identity", key)
+		}
+	}
+}
+
+func TestAnonymizePseudonymsOneToOne(t *testing.T) {
+	// Unresolved neighbours are keyed by -PCI: every 4G PCI must stay a cell
+	// of its own, although they fill the pseudonym range.
+	survey := SurveyInfo{Surveys: make(SurveyMap)}
+	for pci := 1; pci < 504; pci++ {
+		data := SurveyData{Network: "4G", NetName: "Orange", Band: 800, CellID: -pci, PCI: pci, DBM: -90}
+		survey.Surveys[cellKey(data)] = SurveyDataSlice{data}
+	}
+	anonymizer, err := NewAnonymizer(AnonymizeOptions{Key: []byte("test"), FixedShift: true, Cells: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := anonymizer.Anonymize(survey)
+	if len(out.Surveys) != len(survey.Surveys) {
+		t.Errorf("%d cells after anonymisation, want %d", len(out.Surveys), len(survey.Surveys))
+	}
+	pcis := make(map[int]bool)
+	for _, slice := range out.Surveys {
+		pci := slice[0].PCI
+		if pci < 1 || pci >= 504 || pcis[pci] {
+			t.Errorf("PCI pseudonym %d out of range or given twice", pci)
+		}
+		pcis[pci] = true
+	}
+
+	// The same Anonymizer gives the same pseudonyms to the next survey.
+	again := anonymizer.Anonymize(survey)
+	for key := range out.Surveys {
+		if _, ok := again.Surveys[key]; !ok {
+			t.Errorf("cell %v not given the same pseudonym twice", key)
+		}
+	}
+}
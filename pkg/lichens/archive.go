@@ -0,0 +1,103 @@
+package lichens
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// isZipFilename reports whether filename names a zip archive of surveys.
+func isZipFilename(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".zip")
+}
+
+// trimGzipExt removes a ".gz" extension, so "L3240918.csv.gz" is checked and
+// listed as "L3240918.csv".
+func trimGzipExt(filename string) string {
+	if strings.EqualFold(filepath.Ext(filename), ".gz") {
+		return filename[:len(filename)-len(".gz")]
+	}
+	return filename
+}
+
+// decompress returns a reader of the uncompressed survey when r holds gzip
+// data, whatever the file is called, and r itself otherwise.
+func decompress(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(buffered)
+	}
+	return buffered, nil
+}
+
+// ReadSurveyFile reads every survey held in filename: one for a plain or gzip
+// compressed file, one per survey entry of a zip archive. The format of each
+// survey is sniffed with ReadSurvey. The results are keyed by filename, or by
+// "archive.zip/entry.csv" for archive entries.
+func ReadSurveyFile(filename string, opts ParseOptions) (map[string]SurveyInfo, error) {
+	if !isZipFilename(filename) {
+		survey, err := readSurveyPath(filename, opts, ReadSurvey)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]SurveyInfo{filename: survey}, nil
+	}
+
+	archive, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	surveys := make(map[string]SurveyInfo)
+	var errs []error
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || !isSurveyEntry(entry.Name) {
+			continue
+		}
+		name := filename + "/" + entry.Name
+		survey, err := readZipEntry(entry, name, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		surveys[name] = survey
+	}
+	if len(surveys) == 0 && len(errs) == 0 {
+		return nil, fmt.Errorf("no survey files in archive %s", filename)
+	}
+	return surveys, errors.Join(errs...)
+}
+
+// isSurveyEntry selects the L*.CSV survey files of an archive, compressed or
+// not, wherever they sit in its tree.
+func isSurveyEntry(name string) bool {
+	base := trimGzipExt(path.Base(name))
+	return strings.EqualFold(filepath.Ext(base), ".csv") && strings.HasPrefix(strings.ToUpper(base), "L")
+}
+
+func readZipEntry(entry *zip.File, name string, opts ParseOptions) (SurveyInfo, error) {
+	if opts.CheckFilename && !isValidSirettaLFilename(trimGzipExt(path.Base(entry.Name))) {
+		return SurveyInfo{}, fmt.Errorf("invalid filename pattern: %s", entry.Name)
+	}
+
+	input, err := entry.Open()
+	if err != nil {
+		return SurveyInfo{}, err
+	}
+	defer input.Close()
+
+	r, err := decompress(input)
+	if err != nil {
+		return SurveyInfo{}, err
+	}
+	opts.Name = name
+	return ReadSurvey(r, opts)
+}
@@ -0,0 +1,97 @@
+package lichens
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeZip(t *testing.T, filename string, entries map[string][]byte) {
+	t.Helper()
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(file)
+	for name, data := range entries {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadCompressedSurveys(t *testing.T) {
+	dir := t.TempDir()
+	survey := testSurvey(testColumns, testRow)
+
+	gz := filepath.Join(dir, "L3240918.csv.gz")
+	if err := os.WriteFile(gz, gzipped(t, survey), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	single := filepath.Join(dir, "single.zip")
+	writeZip(t, single, map[string][]byte{"L3240918.CSV": []byte(survey), "notes.txt": []byte("not a survey")})
+	archive := filepath.Join(dir, "site.zip")
+	writeZip(t, archive, map[string][]byte{
+		"L3240918.CSV":         []byte(survey),
+		"day2/L3241030.CSV.gz": gzipped(t, survey),
+		"day2/readme.csv":      []byte("not a survey"),
+		"day2/":                nil,
+	})
+
+	for _, test := range []struct {
+		filename string
+		names    []string
+	}{
+		{gz, []string{gz}},
+		{single, []string{single + "/L3240918.CSV"}},
+		{archive, []string{archive + "/L3240918.CSV", archive + "/day2/L3241030.CSV.gz"}},
+	} {
+		surveys, err := ReadSurveyFile(test.filename, ParseOptions{CheckFilename: true})
+		if err != nil {
+			t.Fatalf("%s: %v", test.filename, err)
+		}
+		if len(surveys) != len(test.names) {
+			t.Errorf("%s: read %v, want %v", test.filename, SortedFilenames(surveys), test.names)
+		}
+		for _, name := range test.names {
+			if s, ok := surveys[name]; !ok || len(s.Surveys) != 1 {
+				t.Errorf("%s: survey %s missing or empty", test.filename, name)
+			}
+		}
+	}
+
+	for _, filename := range []string{gz, single} {
+		if _, err := ReadMultiCSV(filename, ParseOptions{CheckFilename: true}); err != nil {
+			t.Errorf("ReadMultiCSV(%s): %v", filename, err)
+		}
+	}
+	if _, err := ReadMultiCSV(archive, ParseOptions{}); err == nil {
+		t.Error("ReadMultiCSV read an archive of two surveys")
+	}
+}
@@ -0,0 +1,395 @@
+package lichens
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ATRoundGap is the longest time between the responses of one survey round in
+// an AT command capture.
+const ATRoundGap = 5 * time.Second
+
+// OperatorNames maps a PLMN ("20801") to the operator name used in the survey
+// keys, so that modem captures, which only report MCC and MNC, share keys
+// with the GRAPHYTE surveys. Unknown PLMNs are named "MCC-MNC".
+var OperatorNames = map[string]string{
+	"20801": "Orange",
+	"20810": "SFR",
+	"20815": "Free",
+	"20820": "Bouygues",
+	"23410": "O2",
+	"23415": "Vodafone",
+	"23420": "3",
+	"23430": "EE",
+}
+
+func operatorName(mcc, mnc int) string {
+	plmn := fmt.Sprintf("%03d%02d", mcc, mnc)
+	if name, ok := OperatorNames[plmn]; ok {
+		return name
+	}
+	return fmt.Sprintf("%03d-%02d", mcc, mnc)
+}
+
+// atTimestampPattern matches the timestamp that capture tools put in front of
+// each line, "2023-03-24 09:20:03", optionally bracketed, with fractions or Z.
+var atTimestampPattern = regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2})[ T](\d{2}:\d{2}:\d{2})(?:\.\d+)?Z?\]?\s*`)
+
+// ATCaptureReader reads text logs of modem AT command responses: Quectel
+// AT+QENG="servingcell" and AT+QENG="neighbourcell", and u-blox AT+UCGED
+// mode 2. Responses logged within ATRoundGap of each other form a round; a
+// second serving cell also starts a new round. Lines without a timestamp take
+// the one of the previous line.
+type ATCaptureReader struct {
+	Options ParseOptions
+}
+
+// Sniff recognises Quectel and u-blox engineering mode responses.
+func (a ATCaptureReader) Sniff(head []byte) bool {
+	return bytes.Contains(head, []byte("+QENG:")) || bytes.Contains(head, []byte("+UCGED:"))
+}
+
+func (a ATCaptureReader) WithOptions(opts ParseOptions) SurveyReader {
+	return ATCaptureReader{Options: opts}
+}
+
+// atCapture is the state of an ATCaptureReader while it reads a log.
+type atCapture struct {
+	opts     ParseOptions
+	location *time.Location
+	survey   *SurveyInfo
+	cells    []pendingCell
+
+	round      int
+	roundStart time.Time
+	serving    *SurveyData // serving cell of the current round
+	index      int         // last cell index of the current round
+	timestamp  time.Time
+	ucged      int // lines of a +UCGED response still expected
+	ucgedRAT   string
+	ucgedMCC   int
+	ucgedMNC   int
+}
+
+func (a ATCaptureReader) Read(r io.Reader) (SurveyInfo, error) {
+	opts := a.Options
+	if opts.Name == "" {
+		opts.Name = "AT capture"
+	}
+	location := opts.Location
+	if location == nil {
+		location = time.UTC
+	}
+	survey := SurveyInfo{
+		ApplicationVersion: "AT capture",
+		Filename:           path.Base(opts.Name),
+		Location:           location,
+		Surveys:            make(SurveyMap),
+	}
+	capture := &atCapture{opts: opts, location: location, survey: &survey}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if err := capture.parseLine(scanner.Text(), line); err != nil {
+			var parseErr *ParseError
+			if opts.Mode == ParseLenient && errors.As(err, &parseErr) {
+				survey.Warnings = append(survey.Warnings, ParseWarning{*parseErr})
+				continue
+			}
+			return survey, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return survey, err
+	}
+	if len(capture.cells) == 0 {
+		return survey, &ParseError{File: opts.Name, Err: errors.New("no cell in AT capture")}
+	}
+
+	addCells(&survey, capture.cells)
+	return survey, nil
+}
+
+func (c *atCapture) parseLine(text string, line int) error {
+	if match := atTimestampPattern.FindStringSubmatch(text); match != nil {
+		t, err := time.ParseInLocation("2006-01-02 15:04:05", match[1]+" "+match[2], c.location)
+		if err != nil {
+			return &ParseError{File: c.opts.Name, Line: line, Column: "timestamp", Value: match[0], Err: err}
+		}
+		c.timestamp = t
+		text = text[len(match[0]):]
+	}
+	text = strings.TrimSpace(text)
+	if c.ucged != 0 {
+		// The lines of a +UCGED: 2 response are numbers. Blank lines are
+		// skipped; OK, ERROR, an echoed command or another response end it.
+		if text == "" {
+			return nil
+		}
+		if !strings.ContainsAny(text[:1], "0123456789-") {
+			c.ucged = 0
+		}
+	}
+
+	fields := &atFields{file: c.opts.Name, line: line}
+	switch {
+	case strings.HasPrefix(text, "+QENG:"):
+		fields.split(strings.TrimPrefix(text, "+QENG:"))
+		c.parseQENG(fields)
+	case strings.HasPrefix(text, "+UCGED:"):
+		fields.split(strings.TrimPrefix(text, "+UCGED:"))
+		if fields.int(0, "mode") == 2 {
+			c.ucged = 2
+		}
+	case c.ucged == 2:
+		fields.split(text)
+		c.ucgedRAT = fields.string(0)
+		c.ucgedMCC = fields.int(2, "MCC")
+		c.ucgedMNC = fields.int(3, "MNC")
+		c.ucged = 1
+	case c.ucged == 1:
+		fields.split(text)
+		c.parseUCGED(fields)
+		c.ucged = 0
+	}
+	if fields.err != nil {
+		return fields.err
+	}
+	return nil
+}
+
+// startRound opens a new round when the response is too far from the start
+// of the current one, or when it is a second serving cell.
+func (c *atCapture) startRound(serving bool) {
+	if c.round == 0 || c.timestamp.Sub(c.roundStart) > ATRoundGap || (serving && c.serving != nil) {
+		c.round++
+		c.roundStart = c.timestamp
+		c.serving = nil
+		c.index = 0
+	}
+}
+
+func (c *atCapture) addServing(data SurveyData) {
+	c.startRound(true)
+	data.Survey = c.round
+	data.Timestamp = c.timestamp
+	c.index++
+	data.Index = c.index
+	data.NetName = operatorName(data.MCC, data.MNC)
+	setBand(&data)
+	c.cells = append(c.cells, pendingCell{key: cellKey(data), data: data})
+	c.serving = &data
+}
+
+func (c *atCapture) addNeighbour(data SurveyData) {
+	c.startRound(false)
+	data.Survey = c.round
+	data.Timestamp = c.timestamp
+	c.index++
+	data.Index = c.index
+	if c.serving != nil {
+		data.MCC, data.MNC = c.serving.MCC, c.serving.MNC
+		data.NetName = c.serving.NetName
+	}
+	setBand(&data)
+	c.cells = append(c.cells, pendingCell{key: cellKey(data), data: data, neighbour: true})
+}
+
+// parseQENG reads the Quectel serving and neighbour cell responses.
+func (c *atCapture) parseQENG(f *atFields) {
+	kind := f.string(0)
+	switch {
+	case kind == "servingcell":
+		var data SurveyData
+		switch f.string(2) {
+		case "LTE":
+			if f.len() < 17 {
+				return // searching or limited service, no cell reported
+			}
+			data.Network = "4G"
+			data.MCC, data.MNC = f.int(4, "MCC"), f.int(5, "MNC")
+			data.CellID = f.hex(6, "cellID")
+			data.PCI = f.int(7, "PCID")
+			data.XRFCN = f.int(8, "earfcn")
+			data.LACTAC = f.hex(12, "TAC")
+			data.RSRP, data.RSRQ = f.optionalFloat(13, "RSRP"), f.optionalFloat(14, "RSRQ")
+			data.DBM = f.float(15, "RSSI")
+			data.SINR = f.optionalFloat(16, "SINR")
+		case "NR5G-SA":
+			if f.len() < 16 {
+				return
+			}
+			data.Network = "5G"
+			data.MCC, data.MNC = f.int(4, "MCC"), f.int(5, "MNC")
+			data.CellID = f.hex(6, "cellID")
+			data.PCI = f.int(7, "PCID")
+			data.LACTAC = f.hex(8, "TAC")
+			data.XRFCN = f.int(9, "ARFCN")
+			data.RSRP, data.RSRQ = f.optionalFloat(12, "RSRP"), f.optionalFloat(13, "RSRQ")
+			data.SINR = f.optionalFloat(14, "SINR")
+			data.SCS = 15 << f.int(15, "scs")
+			data.DBM = data.RSRP.Value
+		case "WCDMA":
+			if f.len() < 12 {
+				return
+			}
+			data.Network = "3G"
+			data.MCC, data.MNC = f.int(3, "MCC"), f.int(4, "MNC")
+			data.LACTAC = f.hex(5, "LAC")
+			data.CellID = f.hex(6, "cellID")
+			data.XRFCN = f.int(7, "uarfcn")
+			data.SCR = OptionalInt{Value: f.int(8, "PSC"), Valid: true}
+			data.RSCP, data.ECIO = f.optionalFloat(10, "RSCP"), f.optionalFloat(11, "ecio")
+			data.DBM = data.RSCP.Value
+		case "GSM":
+			if f.len() < 11 {
+				return
+			}
+			data.Network = "2G"
+			data.MCC, data.MNC = f.int(3, "MCC"), f.int(4, "MNC")
+			data.LACTAC = f.hex(5, "LAC")
+			data.CellID = f.hex(6, "cellID")
+			data.BSIC = BSIC(OptionalInt{Value: f.int(7, "bsic"), Valid: true})
+			data.XRFCN = f.int(8, "arfcn")
+			data.DBM = f.float(10, "rxlev")
+		default:
+			return
+		}
+		if f.err == nil {
+			c.addServing(data)
+		}
+	case strings.HasPrefix(kind, "neighbourcell"):
+		var data SurveyData
+		switch f.string(1) {
+		case "LTE":
+			if f.len() < 8 {
+				return
+			}
+			data.Network = "4G"
+			data.XRFCN = f.int(2, "earfcn")
+			data.PCI = f.int(3, "PCID")
+			data.RSRQ, data.RSRP = f.optionalFloat(4, "RSRQ"), f.optionalFloat(5, "RSRP")
+			data.DBM = f.float(6, "RSSI")
+			data.SINR = f.optionalFloat(7, "SINR")
+		case "WCDMA":
+			if f.len() < 9 {
+				return
+			}
+			data.Network = "3G"
+			data.XRFCN = f.int(2, "uarfcn")
+			data.PCI = f.int(6, "PSC")
+			data.SCR = OptionalInt{Value: data.PCI, Valid: true}
+			data.RSCP, data.ECIO = f.optionalFloat(7, "RSCP"), f.optionalFloat(8, "ecno")
+			data.DBM = data.RSCP.Value
+		default:
+			return
+		}
+		if f.err == nil {
+			c.addNeighbour(data)
+		}
+	}
+}
+
+// parseUCGED reads the LTE cell line of a u-blox +UCGED: 2 response. RSRP and
+// RSRQ are reported as 3GPP indexes.
+func (c *atCapture) parseUCGED(f *atFields) {
+	switch c.ucgedRAT {
+	case "4", "5", "6", "7":
+	default:
+		return // only the LTE layout is known
+	}
+	if f.len() < 13 {
+		return
+	}
+	data := SurveyData{Network: "4G", MCC: c.ucgedMCC, MNC: c.ucgedMNC}
+	data.XRFCN = f.int(0, "EARFCN")
+	data.LACTAC = f.hex(4, "tac")
+	data.CellID = f.hex(5, "LcellId")
+	data.PCI = f.int(6, "P-CID")
+	data.RSRP = ReportedFloat(float64(f.int(10, "rsrp")) - 141)
+	data.RSRQ = ReportedFloat(float64(f.int(11, "rsrq"))/2 - 20)
+	data.SINR = f.optionalFloat(12, "Lsinr")
+	data.DBM = data.RSRP.Value
+	if f.err == nil {
+		c.addServing(data)
+	}
+}
+
+// atFields holds the comma separated values of a response and keeps the first
+// conversion error.
+type atFields struct {
+	values []string
+	file   string
+	line   int
+	err    *ParseError
+}
+
+func (f *atFields) split(text string) {
+	f.values = strings.Split(strings.TrimSpace(text), ",")
+	for i, value := range f.values {
+		f.values[i] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+}
+
+func (f *atFields) len() int {
+	return len(f.values)
+}
+
+func (f *atFields) string(i int) string {
+	if i >= len(f.values) {
+		return ""
+	}
+	return f.values[i]
+}
+
+func (f *atFields) fail(name, value string, err error) {
+	if f.err == nil {
+		f.err = &ParseError{File: f.file, Line: f.line, Column: name, Value: value, Err: err}
+	}
+}
+
+func (f *atFields) int(i int, name string) int {
+	value := f.string(i)
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		f.fail(name, value, err)
+	}
+	return n
+}
+
+func (f *atFields) hex(i int, name string) int {
+	value := f.string(i)
+	n, err := strconv.ParseInt(value, 16, 64)
+	if err != nil {
+		f.fail(name, value, err)
+	}
+	return int(n)
+}
+
+func (f *atFields) float(i int, name string) float64 {
+	value := f.string(i)
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		f.fail(name, value, err)
+	}
+	return v
+}
+
+// optionalFloat treats the "-" Quectel writes for unknown values as not
+// reported.
+func (f *atFields) optionalFloat(i int, name string) OptionalFloat {
+	if value := f.string(i); value == "" || value == notReported {
+		return OptionalFloat{}
+	}
+	return ReportedFloat(f.float(i, name))
+}
@@ -0,0 +1,55 @@
+package lichens
+
+import (
+	"errors"
+	"testing"
+)
+
+// testdata/atcapture.log has Quectel responses with an invalid RSRP on line
+// 8, a u-blox +UCGED: 2 response with blank lines between its lines, and one
+// ended by ERROR before the Quectel response of line 17.
+func TestATCaptureReader(t *testing.T) {
+	const fixture = "testdata/atcapture.log"
+
+	_, err := readFixture(t, ATCaptureReader{}, fixture)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 8 || parseErr.Column != "RSRP" {
+		t.Errorf("strict error %v, want a ParseError on line 8 column RSRP", err)
+	}
+
+	survey, err := readFixture(t, ATCaptureReader{Options: ParseOptions{Mode: ParseLenient}}, fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(survey.Warnings) != 1 || survey.Warnings[0].Line != 8 {
+		t.Errorf("Warnings = %v, want one on line 8", survey.Warnings)
+	}
+	if len(survey.Rounds) != 5 {
+		t.Errorf("%d rounds, want 5", len(survey.Rounds))
+	}
+
+	for _, test := range []struct {
+		key     SurveyKey
+		samples int
+		rsrp    float64
+	}{
+		{SurveyKey{Band: 800, CellID: 0x1A2D003, NetName: "Orange", NetworkType: "4G"}, 3, -95},
+		{SurveyKey{Band: 800, CellID: -311, NetName: "Orange", NetworkType: "4G"}, 1, -101},
+		{SurveyKey{Band: 1800, CellID: -100, NetName: "Orange", NetworkType: "4G"}, 1, -108},
+		{SurveyKey{Band: 3500, CellID: 0x12345678A, NetName: "Orange", NetworkType: "5G"}, 1, -88},
+		// rsrp index 45 is -96 dBm.
+		{SurveyKey{Band: 800, CellID: 0x1B3C01, NetName: "SFR", NetworkType: "4G"}, 1, -96},
+	} {
+		slice := survey.Surveys[test.key]
+		if len(slice) != test.samples {
+			t.Errorf("%v: %d samples, want %d", test.key, len(slice), test.samples)
+			continue
+		}
+		if slice[0].RSRP != ReportedFloat(test.rsrp) {
+			t.Errorf("%v: RSRP %v, want %v", test.key, slice[0].RSRP, test.rsrp)
+		}
+	}
+	if len(survey.Surveys) != 5 {
+		t.Errorf("%d cells, want 5", len(survey.Surveys))
+	}
+}
@@ -0,0 +1,90 @@
+package lichens
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// AverageMode selects how Stats.Mean averages the samples of a cell.
+type AverageMode int
+
+const (
+	// AverageDB is the arithmetic mean of the dB values.
+	AverageDB AverageMode = iota
+	// AverageLinear averages the power levels in mW and converts the result
+	// back to dBm, which gives strong samples their physical weight. Quality
+	// metrics (RSRQ, Ec/Io, SINR) and the Siretta RSSI scale keep the dB mean.
+	AverageLinear
+	// AverageMedian reports the median.
+	AverageMedian
+)
+
+// ParseAverageMode converts "db", "linear" or "median" to an AverageMode.
+func ParseAverageMode(s string) (AverageMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "db", "arithmetic":
+		return AverageDB, nil
+	case "linear", "mw", "power":
+		return AverageLinear, nil
+	case "median":
+		return AverageMedian, nil
+	default:
+		return AverageDB, fmt.Errorf("unknown average mode %q, expected db, linear or median", s)
+	}
+}
+
+func (m AverageMode) String() string {
+	switch m {
+	case AverageLinear:
+		return "linear"
+	case AverageMedian:
+		return "median"
+	default:
+		return "dB"
+	}
+}
+
+// Title is the mode as stated in the table titles.
+func (m AverageMode) Title() string {
+	switch m {
+	case AverageLinear:
+		return "linear power average"
+	case AverageMedian:
+		return "median"
+	default:
+		return "dB average"
+	}
+}
+
+// StatOptions controls how the calculators summarise samples.
+type StatOptions struct {
+	Average    AverageMode
+	Confidence float64 // level of the confidence intervals, DefaultConfidence if unset
+	Bootstrap  int     // bootstrap resamples, 0 for the t interval
+	MaxCI      float64 // half-width in dB above which the tables flag a mean, 0 for none
+}
+
+// powerMetrics are the metrics in dBm that AverageLinear averages in mW.
+var powerMetrics = map[string]bool{"DBM": true, "RSCP": true, "RSRP": true}
+
+// average returns the Mean of s in the given mode. With censored samples the
+// linear average is that of the fitted log-normal power, μ + σ²·ln10/20 in dBm.
+func average(s Stats, observations []Observation, metric string, mode AverageMode) float64 {
+	switch {
+	case s.Number == 0:
+		return s.Mean
+	case mode == AverageMedian:
+		return s.Median
+	case mode == AverageLinear && powerMetrics[metric]:
+		if s.Censored > 0 {
+			return s.Mean + s.Variance*math.Ln10/20
+		}
+		sum := 0.0
+		for _, observation := range observations {
+			sum += math.Pow(10, observation.Value/10)
+		}
+		return 10 * math.Log10(sum/float64(len(observations)))
+	}
+	return s.Mean
+}
@@ -0,0 +1,74 @@
+package lichens
+
+import "testing"
+
+func observationsOf(values ...float64) []Observation {
+	observations := make([]Observation, len(values))
+	for i, value := range values {
+		observations[i] = Observation{Value: value}
+	}
+	return observations
+}
+
+func TestAverage(t *testing.T) {
+	odd := observationsOf(-60, -90, -70)
+	even := observationsOf(-60, -100, -70, -90)
+	censored := []Observation{
+		{Value: -90}, {Value: -95}, {Value: -100}, {Value: -102}, {Value: -104},
+		{Value: -106, Censored: true}, {Value: -106, Censored: true}, {Value: -106, Censored: true},
+	}
+	tests := []struct {
+		name         string
+		observations []Observation
+		metric       string
+		mode         AverageMode
+		want         float64
+	}{
+		{"db", odd, "RSRP", AverageDB, -220.0 / 3},
+		{"median odd", odd, "RSRP", AverageMedian, -70},
+		{"median even", even, "RSRP", AverageMedian, -80},
+		// 10·log10((10⁻⁶ + 10⁻⁹ + 10⁻⁷) / 3) dBm
+		{"linear", odd, "RSRP", AverageLinear, -64.3533393575},
+		{"linear dbm", odd, "DBM", AverageLinear, -64.3533393575},
+		{"linear rscp", odd, "RSCP", AverageLinear, -64.3533393575},
+		// Quality metrics keep the dB mean.
+		{"linear rsrq", observationsOf(-10, -12, -17), "RSRQ", AverageLinear, -13},
+		{"linear rssi", odd, "RSSI", AverageLinear, -220.0 / 3},
+		// Tobit fit of censor_test.go, μ + σ²·ln10/20 with μ -103.1848, σ 8.0400.
+		{"linear censored", censored, "RSRP", AverageLinear, -95.7427},
+		{"db censored", censored, "RSRP", AverageDB, -103.1848},
+		{"single sample", observationsOf(-85), "RSRP", AverageLinear, -85},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := estimate(test.observations, test.metric, test.mode).Mean; !almostEqual(got, test.want, 1e-3) {
+				t.Errorf("%s %s average = %v, want %v", test.metric, test.mode, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAverageNoSamples(t *testing.T) {
+	for _, mode := range []AverageMode{AverageDB, AverageLinear, AverageMedian} {
+		stats := calculateMetrics(nil, StatOptions{Average: mode}, "RSRP")["RSRP"]
+		if stats.Number != 0 || stats.Mean != 0 || stats.CILow != 0 || stats.CIHigh != 0 {
+			t.Errorf("%s: Stats of no samples = %+v, want zero", mode, stats)
+		}
+		if cell := ciCell(stats, 3); cell != "-" {
+			t.Errorf("%s: ciCell of no samples = %q, want \"-\"", mode, cell)
+		}
+	}
+}
+
+func TestParseAverageMode(t *testing.T) {
+	for input, want := range map[string]AverageMode{
+		"": AverageDB, "dB": AverageDB, "linear": AverageLinear, " mW ": AverageLinear, "Median": AverageMedian,
+	} {
+		if got, err := ParseAverageMode(input); err != nil || got != want {
+			t.Errorf("ParseAverageMode(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseAverageMode("geometric"); err == nil {
+		t.Error("ParseAverageMode(\"geometric\") succeeded, want an error")
+	}
+}
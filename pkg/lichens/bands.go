@@ -0,0 +1,104 @@
+package lichens
+
+// lteBand describes the downlink EARFCN range of an LTE band, 3GPP TS 36.101.
+type lteBand struct {
+	band      int
+	first     int     // first downlink EARFCN of the band
+	last      int     // last downlink EARFCN of the band
+	lowDL     float64 // downlink frequency of the first EARFCN, in MHz
+	frequency int     // nominal frequency, as written in the Siretta Band column
+}
+
+var lteBands = []lteBand{
+	{1, 0, 599, 2110, 2100},
+	{2, 600, 1199, 1930, 1900},
+	{3, 1200, 1949, 1805, 1800},
+	{4, 1950, 2399, 2110, 1700},
+	{5, 2400, 2649, 869, 850},
+	{7, 2750, 3449, 2620, 2600},
+	{8, 3450, 3799, 925, 900},
+	{12, 5010, 5179, 729, 700},
+	{13, 5180, 5279, 746, 700},
+	{17, 5730, 5849, 734, 700},
+	{20, 6150, 6449, 791, 800},
+	{25, 8040, 8689, 1930, 1900},
+	{26, 8690, 9039, 859, 850},
+	{28, 9210, 9659, 758, 700},
+	{32, 9920, 10359, 1452, 1500},
+	{38, 37750, 38249, 2570, 2600},
+	{40, 38650, 39649, 2300, 2300},
+	{41, 39650, 41589, 2496, 2500},
+	{42, 41590, 43589, 3400, 3500},
+	{66, 66436, 67335, 2110, 1700},
+	{71, 68586, 68935, 617, 600},
+}
+
+// earfcnBand returns the LTE band of a downlink EARFCN, its nominal frequency
+// and the downlink carrier frequency in MHz.
+func earfcnBand(earfcn int) (band, frequency int, dl float64, ok bool) {
+	for _, b := range lteBands {
+		if earfcn >= b.first && earfcn <= b.last {
+			return b.band, b.frequency, b.lowDL + 0.1*float64(earfcn-b.first), true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// nrBand describes the downlink frequency range of an NR band, 3GPP TS 38.101.
+type nrBand struct {
+	band   int
+	lowDL  float64 // MHz
+	highDL float64 // MHz
+}
+
+// nrBands is ordered so that the European allocation wins where bands overlap.
+var nrBands = []nrBand{
+	{1, 2110, 2170},
+	{3, 1805, 1880},
+	{7, 2620, 2690},
+	{8, 925, 960},
+	{20, 791, 821},
+	{28, 758, 803},
+	{38, 2570, 2620},
+	{40, 2300, 2400},
+	{41, 2496, 2690},
+	{78, 3300, 3800},
+	{77, 3300, 4200},
+	{79, 4400, 5000},
+	{258, 24250, 27500},
+	{257, 26500, 29500},
+}
+
+// nrARFCNBand returns the NR band of a downlink NR-ARFCN and its nominal
+// frequency.
+func nrARFCNBand(arfcn int) (band, frequency int, ok bool) {
+	dl := NRARFCNFrequency(arfcn)
+	for _, b := range nrBands {
+		if dl >= b.lowDL && dl <= b.highDL {
+			return b.band, nrBandFrequency[b.band], true
+		}
+	}
+	return 0, 0, false
+}
+
+// legacyBand returns the nominal frequency of the common GSM ARFCN and UMTS
+// UARFCN downlink ranges.
+func legacyBand(networkType string, arfcn int) int {
+	switch networkType {
+	case "2G":
+		switch {
+		case arfcn >= 1 && arfcn <= 124, arfcn >= 975 && arfcn <= 1023:
+			return 900
+		case arfcn >= 512 && arfcn <= 885:
+			return 1800
+		}
+	case "3G":
+		switch {
+		case arfcn >= 10562 && arfcn <= 10838:
+			return 2100
+		case arfcn >= 2937 && arfcn <= 3088:
+			return 900
+		}
+	}
+	return 0
+}
@@ -0,0 +1,126 @@
+package lichens
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ExpandSurveyPattern resolves a CLI survey argument to file names. It accepts
+// StdinName, a single file, a directory (every .csv, .csv.gz and .zip file in
+// it) or a glob.
+func ExpandSurveyPattern(pattern string) ([]string, error) {
+	if pattern == StdinName {
+		return []string{StdinName}, nil
+	}
+
+	if info, err := os.Stat(pattern); err == nil {
+		if !info.IsDir() {
+			return []string{pattern}, nil
+		}
+		entries, err := os.ReadDir(pattern)
+		if err != nil {
+			return nil, err
+		}
+		var filenames []string
+		for _, entry := range entries {
+			if !entry.IsDir() && isSurveyFilename(entry.Name()) {
+				filenames = append(filenames, filepath.Join(pattern, entry.Name()))
+			}
+		}
+		if len(filenames) == 0 {
+			return nil, fmt.Errorf("no survey files in directory %s", pattern)
+		}
+		return filenames, nil
+	}
+
+	filenames, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("no survey files match %s", pattern)
+	}
+	sort.Strings(filenames)
+	return filenames, nil
+}
+
+// isSurveyFilename reports whether a directory entry looks like a survey file,
+// plain, gzip compressed or a zip archive of surveys.
+func isSurveyFilename(name string) bool {
+	return strings.EqualFold(filepath.Ext(trimGzipExt(name)), ".csv") || isZipFilename(name)
+}
+
+// ReadSurveyDir parses the survey files matched by pattern concurrently with
+// the default ParseOptions. See ReadSurveyDirWithOptions.
+func ReadSurveyDir(pattern string, workers int) (map[string]SurveyInfo, error) {
+	return ReadSurveyDirWithOptions(pattern, workers, ParseOptions{})
+}
+
+// ReadSurveyDirWithOptions parses the survey files matched by pattern with up
+// to workers goroutines, runtime.NumCPU() when workers is not positive. The
+// results are keyed by filename, see ReadSurveyFile for archives. A file that
+// fails does not stop the others: the surveys read are returned together with
+// the joined per-file errors.
+func ReadSurveyDirWithOptions(pattern string, workers int, opts ParseOptions) (map[string]SurveyInfo, error) {
+	filenames, err := ExpandSurveyPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(filenames) {
+		workers = len(filenames)
+	}
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]SurveyInfo, len(filenames))
+	var errs []error
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filename := range jobs {
+				fileOpts := opts
+				fileOpts.Name = ""
+				surveys, err := ReadSurveyFile(filename, fileOpts)
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", filename, err))
+				}
+				for name, survey := range surveys {
+					results[name] = survey
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, filename := range filenames {
+		jobs <- filename
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return results, errors.Join(errs...)
+}
+
+// SortedFilenames returns the filename keys of a batch result in order.
+func SortedFilenames[V any](surveys map[string]V) []string {
+	filenames := make([]string, 0, len(surveys))
+	for filename := range surveys {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
@@ -0,0 +1,188 @@
+package lichens
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+)
+
+// MetricCalculator computes the statistics of the samples of one cell, keyed
+// by metric name ("DBM", "RSRP", ...). SurveyStatGen picks the calculator
+// registered for the network type of the cell. Each Stats must keep in
+// Samples the Number observations it was computed from: CalculateDelta
+// compares the surveys on them.
+type MetricCalculator interface {
+	Calculate(data SurveyDataSlice) map[string]Stats
+}
+
+// ConfigurableCalculator is implemented by calculators that honour
+// StatOptions.
+type ConfigurableCalculator interface {
+	MetricCalculator
+	// WithOptions returns a calculator using opts.
+	WithOptions(opts StatOptions) MetricCalculator
+}
+
+var (
+	calculatorsMu sync.RWMutex
+	calculators   = make(map[string]MetricCalculator)
+)
+
+func init() {
+	RegisterCalculator("2G", &TwoGCalculator{})
+	RegisterCalculator("3G", &ThreeGCalculator{})
+	RegisterCalculator("4G", &FourGCalculator{})
+	RegisterCalculator("5G", &FiveGCalculator{})
+}
+
+// calculatorName normalises the network type a calculator is registered for,
+// so "LTE" and "4G" name the same calculator.
+func calculatorName(networkType string) string {
+	if gsmaType, err := ParseGSMAType(networkType); err == nil {
+		return gsmaType.String()
+	}
+	return networkType
+}
+
+// RegisterCalculator sets the calculator of a network type, replacing the
+// built-in one or adding a network type of its own.
+func RegisterCalculator(networkType string, calculator MetricCalculator) {
+	calculatorsMu.Lock()
+	defer calculatorsMu.Unlock()
+	calculators[calculatorName(networkType)] = calculator
+}
+
+// Calculator returns the calculator registered for a network type.
+func Calculator(networkType string) (MetricCalculator, bool) {
+	calculatorsMu.RLock()
+	defer calculatorsMu.RUnlock()
+	calculator, ok := calculators[calculatorName(networkType)]
+	return calculator, ok
+}
+
+// TwoGCalculator summarises GSM rows, which only report the received level.
+type TwoGCalculator struct {
+	Options StatOptions
+}
+
+func (c *TwoGCalculator) Calculate(data SurveyDataSlice) map[string]Stats {
+	return calculateMetrics(data, c.Options, "DBM", "RSSI")
+}
+
+func (c *TwoGCalculator) WithOptions(opts StatOptions) MetricCalculator {
+	return &TwoGCalculator{Options: opts}
+}
+
+// ThreeGCalculator summarises UMTS rows with the RSCP and Ec/Io of the cell.
+type ThreeGCalculator struct {
+	Options StatOptions
+}
+
+func (c *ThreeGCalculator) Calculate(data SurveyDataSlice) map[string]Stats {
+	return calculateMetrics(data, c.Options, "DBM", "RSSI", "RSCP", "ECIO")
+}
+
+func (c *ThreeGCalculator) WithOptions(opts StatOptions) MetricCalculator {
+	return &ThreeGCalculator{Options: opts}
+}
+
+// FourGCalculator summarises LTE rows. SINR is only present for the sources
+// that report it.
+type FourGCalculator struct {
+	Options StatOptions
+}
+
+func (c *FourGCalculator) Calculate(data SurveyDataSlice) map[string]Stats {
+	return calculateMetrics(data, c.Options, "DBM", "RSSI", "RSRP", "RSRQ", "SINR")
+}
+
+func (c *FourGCalculator) WithOptions(opts StatOptions) MetricCalculator {
+	return &FourGCalculator{Options: opts}
+}
+
+// FiveGCalculator summarises NR rows. RSRP, RSRQ and SINR hold the SS-RSRP,
+// SS-RSRQ and SS-SINR of the cell.
+type FiveGCalculator struct {
+	Options StatOptions
+}
+
+func (c *FiveGCalculator) Calculate(data SurveyDataSlice) map[string]Stats {
+	return calculateMetrics(data, c.Options, "DBM", "RSSI", "RSRP", "RSRQ", "SINR")
+}
+
+func (c *FiveGCalculator) WithOptions(opts StatOptions) MetricCalculator {
+	return &FiveGCalculator{Options: opts}
+}
+
+// calculateMetrics computes the Stats of each metric over the rows that
+// reported it, floor readings being censored. Mean is averaged as selected
+// by opts, with its confidence interval.
+func calculateMetrics(data SurveyDataSlice, opts StatOptions, metrics ...string) map[string]Stats {
+	result := make(map[string]Stats, len(metrics))
+	for _, metric := range metrics {
+		observations := data.Observations(metric)
+		stats := estimate(observations, metric, opts.Average)
+		stats.Samples = observations
+		if stats.Number > 0 {
+			stats.CILow, stats.CIHigh = confidenceInterval(stats, observations, metric, opts)
+		}
+		result[metric] = stats
+	}
+	return result
+}
+
+// newStats computes the Stats of values. The moments that need more samples
+// than given, the spread of a single sample, the skewness of fewer than 3 and
+// the kurtosis of fewer than 4, are left at 0 rather than NaN.
+func newStats(values []float64) Stats {
+	var s Stats
+	s.Number = uint(len(values))
+	if len(values) == 0 {
+		return s
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	s.Min = floats.Min(sorted)
+	s.Max = floats.Max(sorted)
+	s.Range = s.Max - s.Min
+	s.Mean, s.StandardDeviation = stat.MeanStdDev(sorted, nil)
+	s.Variance = s.StandardDeviation * s.StandardDeviation
+	s.Median = median(sorted)
+	s.Quartiles = [3]float64{stat.Quantile(0.25, stat.LinInterp, sorted, nil), s.Median, stat.Quantile(0.75, stat.LinInterp, sorted, nil)}
+	for i, rank := range PercentileRanks {
+		s.Percentiles[i] = stat.Quantile(rank/100, stat.LinInterp, sorted, nil)
+	}
+	s.Mode, _ = stat.Mode(sorted, nil)
+	if len(values) == 1 {
+		// A single sample has no spread, MeanStdDev returns NaN.
+		s.StandardDeviation, s.Variance = 0, 0
+	}
+	if len(values) > 2 && s.StandardDeviation > 0 {
+		s.Skewness = stat.Skew(sorted, nil)
+	}
+	if len(values) > 3 && s.StandardDeviation > 0 {
+		s.Kurtosis = stat.ExKurtosis(sorted, nil)
+	}
+	if math.IsNaN(s.Skewness) {
+		s.Skewness = 0
+	}
+	if math.IsNaN(s.Kurtosis) {
+		s.Kurtosis = 0
+	}
+	return s
+}
+
+// median returns the middle value of sorted values, the mean of the two
+// middle ones for an even count. stat.Quantile interpolates between order
+// statistics and misses the middle value of an odd count.
+func median(sorted []float64) float64 {
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
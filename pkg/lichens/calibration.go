@@ -0,0 +1,155 @@
+package lichens
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Calibration is the offset in dB added to the levels measured by a device,
+// DBM, RSRP and RSCP, to bring it in line with the reference device. Bands,
+// keyed by band frequency in MHz as in SurveyKey, overrides Offset.
+type Calibration struct {
+	Offset float64
+	Bands  map[int]float64
+}
+
+// CalibrationProfiles holds the Calibration of each device by IMEI.
+type CalibrationProfiles map[string]Calibration
+
+// NormaliseIMEI strips the spreadsheet quote and spaces of a header IMEI.
+func NormaliseIMEI(imei string) string {
+	return strings.TrimPrefix(strings.TrimSpace(imei), "'")
+}
+
+// Lookup returns the profile of a device.
+func (p CalibrationProfiles) Lookup(imei string) (Calibration, bool) {
+	calibration, ok := p[NormaliseIMEI(imei)]
+	return calibration, ok
+}
+
+// OffsetFor returns the offset that applies to a band.
+func (c Calibration) OffsetFor(band int) float64 {
+	if offset, ok := c.Bands[band]; ok {
+		return offset
+	}
+	return c.Offset
+}
+
+// applyCalibration corrects the levels of a survey with the profile of its
+// device, if any, and records it in SurveyInfo.Calibration. RSSI is on the
+// Siretta scale rather than in dBm and is left alone, as are floor readings and
+// levels at the floor, so that they are still censored.
+func applyCalibration(survey *SurveyInfo, profiles CalibrationProfiles) {
+	calibration, ok := profiles.Lookup(survey.IMEINumber)
+	if !ok {
+		return
+	}
+	for key, slice := range survey.Surveys {
+		offset := calibration.OffsetFor(key.Band)
+		if offset == 0 {
+			continue
+		}
+		for i := range slice {
+			data := &slice[i]
+			if data.IsFloorReading() {
+				continue
+			}
+			if data.DBM > MinimumSignalLevel {
+				data.DBM += offset
+			}
+			if data.RSRP.Valid && data.RSRP.Value > FloorRSRP {
+				data.RSRP.Value += offset
+			}
+			if data.RSCP.Valid && data.RSCP.Value > FloorRSCP {
+				data.RSCP.Value += offset
+			}
+		}
+	}
+	// The rounds share the samples by value: rebuild them.
+	survey.Rounds = BuildRounds(survey.Surveys)
+	survey.Calibration = &calibration
+}
+
+// CalibrationResult is the offset derived for one band, or for the whole
+// device when Band is 0, from the cells seen by both devices.
+type CalibrationResult struct {
+	Band   int
+	Offset float64 // mean of the per-cell differences, reference minus device
+	Std    float64 // standard deviation of the per-cell differences
+	Cells  int
+}
+
+// DeriveCalibration compares surveys of a reference device and of another
+// device recorded side by side at one spot. For each cell seen by both with
+// at least minSamples samples each, it takes the difference of the mean DBM;
+// floor readings are left out. It returns the Calibration of the device and
+// the detail per band, device-wide first.
+func DeriveCalibration(reference, device SurveyInfo, minSamples int) (Calibration, []CalibrationResult, error) {
+	byBand := make(map[int][]float64)
+	var all []float64
+	for key, referenceSlice := range reference.Surveys {
+		deviceSlice, ok := device.Surveys[key]
+		if !ok {
+			continue
+		}
+		referenceMean, referenceCount := meanLevel(referenceSlice)
+		deviceMean, deviceCount := meanLevel(deviceSlice)
+		if referenceCount < minSamples || deviceCount < minSamples || referenceCount == 0 || deviceCount == 0 {
+			continue
+		}
+		diff := referenceMean - deviceMean
+		byBand[key.Band] = append(byBand[key.Band], diff)
+		all = append(all, diff)
+	}
+	if len(all) == 0 {
+		return Calibration{}, nil, errors.New("no cell seen by both devices with enough samples")
+	}
+
+	overall := calibrationResult(0, all)
+	calibration := Calibration{Offset: overall.Offset, Bands: make(map[int]float64, len(byBand))}
+	results := []CalibrationResult{overall}
+	bands := make([]int, 0, len(byBand))
+	for band := range byBand {
+		bands = append(bands, band)
+	}
+	sort.Ints(bands)
+	for _, band := range bands {
+		result := calibrationResult(band, byBand[band])
+		calibration.Bands[band] = result.Offset
+		results = append(results, result)
+	}
+	return calibration, results, nil
+}
+
+// meanLevel averages the DBM of the samples that are not floor readings.
+func meanLevel(slice SurveyDataSlice) (float64, int) {
+	sum, n := 0.0, 0
+	for _, data := range slice {
+		if data.IsFloorReading() {
+			continue
+		}
+		sum += data.DBM
+		n++
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return sum / float64(n), n
+}
+
+func calibrationResult(band int, diffs []float64) CalibrationResult {
+	result := CalibrationResult{Band: band, Cells: len(diffs)}
+	for _, diff := range diffs {
+		result.Offset += diff
+	}
+	result.Offset /= float64(len(diffs))
+	if len(diffs) > 1 {
+		for _, diff := range diffs {
+			result.Std += (diff - result.Offset) * (diff - result.Offset)
+		}
+		result.Std = math.Sqrt(result.Std / float64(len(diffs)-1))
+	}
+	return result
+}
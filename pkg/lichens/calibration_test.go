@@ -0,0 +1,60 @@
+package lichens
+
+import "testing"
+
+func TestApplyCalibration(t *testing.T) {
+	key4G := SurveyKey{Band: 800, CellID: 1, NetName: "Orange", NetworkType: "4G"}
+	key3G := SurveyKey{Band: 2100, CellID: 2, NetName: "Orange", NetworkType: "3G"}
+	survey := SurveyInfo{
+		IMEINumber: "'351626102376784",
+		Surveys: SurveyMap{
+			key4G: {
+				{DBM: -80, Percentage: ReportedFloat(50), RSSI: ReportedFloat(40), RSRP: ReportedFloat(-100)},
+				{DBM: -130, Percentage: ReportedFloat(1), RSRP: ReportedFloat(-140)},
+				{DBM: -106, Percentage: ReportedFloat(0), RSSI: ReportedFloat(0), RSRP: ReportedFloat(-140)},
+				{DBM: -90, Percentage: ReportedFloat(40)},
+			},
+			key3G: {
+				{DBM: -85, Percentage: ReportedFloat(45), RSCP: ReportedFloat(-85)},
+				{DBM: -110, Percentage: ReportedFloat(5), RSCP: ReportedFloat(-120)},
+				{DBM: -95, Percentage: ReportedFloat(30)},
+			},
+		},
+	}
+	profiles := CalibrationProfiles{"351626102376784": {Offset: 1.5, Bands: map[int]float64{2100: -2}}}
+	applyCalibration(&survey, profiles)
+
+	if survey.Calibration == nil {
+		t.Fatal("Calibration not recorded")
+	}
+	want := map[SurveyKey]SurveyDataSlice{
+		key4G: {
+			// RSSI is on the Siretta scale and left alone.
+			{DBM: -78.5, Percentage: ReportedFloat(50), RSSI: ReportedFloat(40), RSRP: ReportedFloat(-98.5)},
+			// Levels at the floor stay censored.
+			{DBM: -130, Percentage: ReportedFloat(1), RSRP: ReportedFloat(-140)},
+			// A floor reading is left as reported.
+			{DBM: -106, Percentage: ReportedFloat(0), RSSI: ReportedFloat(0), RSRP: ReportedFloat(-140)},
+			// Levels not reported stay so.
+			{DBM: -88.5, Percentage: ReportedFloat(40)},
+		},
+		key3G: {
+			{DBM: -87, Percentage: ReportedFloat(45), RSCP: ReportedFloat(-87)},
+			{DBM: -112, Percentage: ReportedFloat(5), RSCP: ReportedFloat(-120)},
+			{DBM: -97, Percentage: ReportedFloat(30)},
+		},
+	}
+	for key, slice := range want {
+		for i, data := range slice {
+			got := survey.Surveys[key][i]
+			if got.DBM != data.DBM || got.RSSI != data.RSSI || got.RSRP != data.RSRP || got.RSCP != data.RSCP {
+				t.Errorf("%s sample %d: DBM %v RSSI %v RSRP %v RSCP %v, want %v %v %v %v", key.NetworkType, i,
+					got.DBM, got.RSSI, got.RSRP, got.RSCP, data.DBM, data.RSSI, data.RSRP, data.RSCP)
+			}
+		}
+	}
+
+	if observation, _ := survey.Surveys[key3G][1].Observation("RSCP"); !observation.Censored {
+		t.Error("RSCP at the floor not censored")
+	}
+}
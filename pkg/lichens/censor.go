@@ -0,0 +1,141 @@
+package lichens
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// FloorRSRP is the bottom of the RSRP reporting range; the GRAPHYTE reports
+// it for cells below its sensitivity.
+const FloorRSRP = -140.0
+
+// FloorRSCP is the bottom of the UMTS RSCP reporting range.
+const FloorRSCP = -120.0
+
+// Observation is a metric value of one sample. A censored observation is a
+// reading at the sensitivity floor: the true level is at or below Value.
+type Observation struct {
+	Value    float64
+	Censored bool
+}
+
+// IsFloorReading reports whether the sample is a sentinel floor reading, such
+// as "-106 dBm, 0%, RSSI 0, RSRP -140": the modem saw the cell but could not
+// measure it.
+func (d SurveyData) IsFloorReading() bool {
+	return d.Percentage.Valid && d.Percentage.Value == 0
+}
+
+// Observation returns a metric of the sample with its censoring. Every metric
+// of a floor reading is censored at the value reported; otherwise DBM is
+// censored at MinimumSignalLevel, RSRP at FloorRSRP and RSCP at FloorRSCP.
+func (d SurveyData) Observation(metric string) (Observation, bool) {
+	value, ok := d.Metric(metric)
+	if !ok {
+		return Observation{}, false
+	}
+	switch {
+	case d.IsFloorReading():
+		return Observation{Value: value, Censored: true}, true
+	case metric == "DBM" && value <= MinimumSignalLevel:
+		return Observation{Value: MinimumSignalLevel, Censored: true}, true
+	case metric == "RSRP" && value <= FloorRSRP:
+		return Observation{Value: FloorRSRP, Censored: true}, true
+	case metric == "RSCP" && value <= FloorRSCP:
+		return Observation{Value: FloorRSCP, Censored: true}, true
+	}
+	return Observation{Value: value}, true
+}
+
+// Observations returns the reported observations of a metric across the
+// slice.
+func (s SurveyDataSlice) Observations(metric string) []Observation {
+	observations := make([]Observation, 0, len(s))
+	for _, data := range s {
+		if observation, ok := data.Observation(metric); ok {
+			observations = append(observations, observation)
+		}
+	}
+	return observations
+}
+
+// newCensoredStats computes Stats over observations some of which may be
+// left-censored. Mean and StandardDeviation are the Tobit maximum likelihood
+// estimates of a normal distribution, so floor readings pull the mean down
+// without being taken at face value. Min, Max and Range include the floor
+// values. When fewer than two distinct values are measured the model cannot
+// be fitted and the floor values are averaged as they are.
+func newCensoredStats(observations []Observation) Stats {
+	values := make([]float64, len(observations))
+	var measured []float64
+	for i, observation := range observations {
+		values[i] = observation.Value
+		if !observation.Censored {
+			measured = append(measured, observation.Value)
+		}
+	}
+	s := newStats(values)
+	s.Censored = uint(len(observations) - len(measured))
+	if s.Censored == 0 || len(measured) < 2 {
+		return s
+	}
+
+	mean, std := stat.MeanStdDev(measured, nil)
+	if std == 0 {
+		return s
+	}
+	if mean, std, ok := tobit(observations, mean, std); ok {
+		s.Mean, s.StandardDeviation = mean, std
+		s.Variance = std * std
+	}
+	return s
+}
+
+// tobit fits a normal distribution to left-censored observations by maximum
+// likelihood, starting from the mean and standard deviation of the measured
+// values. It runs expectation maximisation: each censored value is replaced
+// by the moments of the normal truncated above it, then the mean and
+// standard deviation are re-estimated, until they settle.
+func tobit(observations []Observation, mean, std float64) (float64, float64, bool) {
+	const (
+		maxIterations = 10000
+		tolerance     = 1e-10
+	)
+	normal := distuv.UnitNormal
+	n := float64(len(observations))
+	for i := 0; i < maxIterations; i++ {
+		sum, sumSquares := 0.0, 0.0
+		for _, observation := range observations {
+			if !observation.Censored {
+				sum += observation.Value
+				sumSquares += observation.Value * observation.Value
+				continue
+			}
+			z := (observation.Value - mean) / std
+			// Inverse Mills ratio; for a floor far below the mean the ratio
+			// tends to -z.
+			lambda := -z
+			if cdf := normal.CDF(z); cdf > 0 {
+				lambda = normal.Prob(z) / cdf
+			}
+			expected := mean - std*lambda
+			variance := std * std * (1 - z*lambda - lambda*lambda)
+			sum += expected
+			sumSquares += variance + expected*expected
+		}
+		nextMean := sum / n
+		nextVariance := sumSquares/n - nextMean*nextMean
+		if math.IsNaN(nextMean) || !(nextVariance > 0) {
+			return 0, 0, false
+		}
+		nextStd := math.Sqrt(nextVariance)
+		done := math.Abs(nextMean-mean) < tolerance && math.Abs(nextStd-std) < tolerance
+		mean, std = nextMean, nextStd
+		if done {
+			return mean, std, true
+		}
+	}
+	return mean, std, true
+}
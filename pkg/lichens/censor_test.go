@@ -0,0 +1,28 @@
+package lichens
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestCensoredStatsTobit(t *testing.T) {
+	observations := []Observation{
+		{Value: -90}, {Value: -95}, {Value: -100}, {Value: -102}, {Value: -104},
+		{Value: -106, Censored: true}, {Value: -106, Censored: true}, {Value: -106, Censored: true},
+	}
+	// Maximum likelihood of a normal left-censored at -106.
+	s := newCensoredStats(observations)
+	if s.Censored != 3 {
+		t.Errorf("Censored = %d, want 3", s.Censored)
+	}
+	if !almostEqual(s.Mean, -103.1848, 1e-3) {
+		t.Errorf("Mean = %v, want -103.1848", s.Mean)
+	}
+	if !almostEqual(s.StandardDeviation, 8.0400, 1e-3) {
+		t.Errorf("StandardDeviation = %v, want 8.0400", s.StandardDeviation)
+	}
+}
@@ -0,0 +1,174 @@
+package lichens
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Siretta survey column names, as found in the column header row without the
+// trailing colon.
+const (
+	ColSurvey     = "Survey"
+	ColTimestamp  = "Timestamp"
+	ColNetwork    = "Network"
+	ColIndex      = "Index"
+	ColXRFCN      = "xRFCN"
+	ColDBM        = "dBm"
+	ColPercentage = "%"
+	ColRSSI       = "RSSI"
+	ColMCC        = "MCC"
+	ColMNC        = "MNC"
+	ColCellID     = "CellID"
+	ColLACTAC     = "LAC/TAC"
+	ColBandNum    = "Band Num"
+	ColBand       = "Band"
+	ColBSIC       = "BSIC"
+	ColSCR        = "SCR"
+	ColECIO       = "ECIO"
+	ColRSCP       = "RSCP"
+	ColPCI        = "PCI"
+	ColRSRP       = "RSRP"
+	ColRSRQ       = "RSRQ"
+	ColBW         = "BW"
+	ColDL         = "DL"
+	ColUL         = "UL"
+	ColNetName    = "Net Name"
+	ColSignal     = "Signal"
+	ColSINR       = "SINR"
+	ColSCS        = "SCS"
+)
+
+// columnAliases maps the 5G column names to the columns they fill. An alias is
+// only used when the header does not also carry the column itself.
+var columnAliases = map[string]string{
+	"ss-rsrp":  ColRSRP,
+	"ss-rsrq":  ColRSRQ,
+	"ss-sinr":  ColSINR,
+	"nr-arfcn": ColXRFCN,
+}
+
+// sirettaColumns is the GRAPHYTE 6.10 column layout, as written by
+// WriteSirettaCSV.
+var sirettaColumns = []string{
+	ColSurvey, ColTimestamp, ColNetwork, ColIndex, ColXRFCN, ColDBM, ColPercentage, ColRSSI,
+	ColMCC, ColMNC, ColCellID, ColLACTAC, ColBandNum, ColBand, ColBSIC, ColSCR, ColECIO,
+	ColRSCP, ColPCI, ColRSRP, ColRSRQ, ColBW, ColDL, ColUL, ColNetName, ColSignal,
+}
+
+// knownColumns lists the columns parsed into SurveyData fields: the GRAPHYTE
+// 6.10 layout, then the 5G columns.
+var knownColumns = append(sirettaColumns[:len(sirettaColumns):len(sirettaColumns)], ColSINR, ColSCS)
+
+// requiredColumns must be present in the column header; the survey key and the
+// statistics cannot be built without them.
+var requiredColumns = []string{ColSurvey, ColTimestamp, ColNetwork, ColDBM, ColCellID, ColBand, ColNetName}
+
+// columnMap gives the record position of each column found in the header row.
+type columnMap struct {
+	index  map[string]int // keyed by normalised column name
+	extras map[int]string // position to column name, for unknown columns
+}
+
+func normaliseColumn(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), ":"))
+}
+
+// isColumnHeader reports whether record is a "Survey:,Timestamp:,..." row. The
+// "Survey:" cell is looked up anywhere in the row so reordered layouts work.
+func isColumnHeader(record []string) bool {
+	for _, field := range record {
+		field = strings.TrimSpace(field)
+		if strings.HasSuffix(field, ":") && normaliseColumn(field) == normaliseColumn(ColSurvey) {
+			return true
+		}
+	}
+	return false
+}
+
+func newColumnMap(header []string) (*columnMap, error) {
+	cols := &columnMap{
+		index:  make(map[string]int),
+		extras: make(map[int]string),
+	}
+
+	known := make(map[string]bool, len(knownColumns))
+	for _, name := range knownColumns {
+		known[normaliseColumn(name)] = true
+	}
+
+	aliased := make(map[string]int)
+	for i, name := range header {
+		norm := normaliseColumn(name)
+		if norm == "" {
+			continue
+		}
+		if _, dup := cols.index[norm]; dup {
+			return nil, fmt.Errorf("duplicate column %q in survey header", name)
+		}
+		cols.index[norm] = i
+		if target, ok := columnAliases[norm]; ok {
+			aliased[normaliseColumn(target)] = i
+		} else if !known[norm] {
+			cols.extras[i] = strings.TrimSuffix(strings.TrimSpace(name), ":")
+		}
+	}
+	for target, i := range aliased {
+		if _, ok := cols.index[target]; !ok {
+			cols.index[target] = i
+		}
+	}
+
+	var missing []string
+	for _, name := range requiredColumns {
+		if !cols.has(name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("survey header is missing required columns: %s", strings.Join(missing, ", "))
+	}
+	return cols, nil
+}
+
+// indexColumns maps every cell of a header row by name, for sources that have
+// no required or known columns.
+func indexColumns(header []string) *columnMap {
+	cols := &columnMap{index: make(map[string]int)}
+	for i, name := range header {
+		if norm := normaliseColumn(name); norm != "" {
+			if _, dup := cols.index[norm]; !dup {
+				cols.index[norm] = i
+			}
+		}
+	}
+	return cols
+}
+
+func (c *columnMap) has(name string) bool {
+	_, ok := c.index[normaliseColumn(name)]
+	return ok
+}
+
+// get returns the trimmed value of the named column, or "" when the column is
+// absent from the header or the record is short.
+func (c *columnMap) get(record []string, name string) string {
+	i, ok := c.index[normaliseColumn(name)]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// extra returns the unknown columns of record keyed by their header name.
+func (c *columnMap) extra(record []string) map[string]string {
+	if len(c.extras) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(c.extras))
+	for i, name := range c.extras {
+		if i < len(record) {
+			values[name] = strings.TrimSpace(record[i])
+		}
+	}
+	return values
+}
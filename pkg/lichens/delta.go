@@ -0,0 +1,283 @@
+package lichens
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// DeltaTest selects the test that sets DeltaStats.AreSignificantlyDiff.
+type DeltaTest int
+
+const (
+	// WelchTest is the Welch t-test on the means, which does not assume equal
+	// variances.
+	WelchTest DeltaTest = iota
+	// MannWhitneyTest is the Mann–Whitney U test on the ranks, for levels that
+	// are not normally distributed, such as those of a fading indoor cell.
+	MannWhitneyTest
+)
+
+// ParseDeltaTest converts "welch" or "mann-whitney" to a DeltaTest.
+func ParseDeltaTest(s string) (DeltaTest, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "welch", "t":
+		return WelchTest, nil
+	case "mann-whitney", "mannwhitney", "u":
+		return MannWhitneyTest, nil
+	default:
+		return WelchTest, fmt.Errorf("unknown test %q, expected welch or mann-whitney", s)
+	}
+}
+
+func (t DeltaTest) String() string {
+	if t == MannWhitneyTest {
+		return "Mann-Whitney U test"
+	}
+	return "Welch t-test"
+}
+
+// Estimator names the location the test compares, and so the Delta reported
+// with it: the mean for the Welch t-test, the median for the Mann–Whitney U
+// test.
+func (t DeltaTest) Estimator() string {
+	if t == MannWhitneyTest {
+		return "median"
+	}
+	return "mean"
+}
+
+// location returns the Estimator of values.
+func (t DeltaTest) location(values []float64) float64 {
+	if t == MannWhitneyTest {
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+		return median(sorted)
+	}
+	return stat.Mean(values, nil)
+}
+
+// DefaultAlpha and DefaultDeltaResamples are used when DeltaOptions leaves
+// them unset.
+const (
+	DefaultAlpha          = 0.05
+	DefaultDeltaResamples = 1000
+)
+
+// DeltaOptions controls the comparison of two surveys.
+type DeltaOptions struct {
+	Alpha     float64   // significance level, DefaultAlpha if unset
+	Test      DeltaTest // test that decides AreSignificantlyDiff
+	Resamples int       // bootstrap resamples of the delta interval, DefaultDeltaResamples if unset
+}
+
+// AlphaLevel returns the significance level.
+func (o DeltaOptions) AlphaLevel() float64 {
+	if o.Alpha <= 0 || o.Alpha >= 1 {
+		return DefaultAlpha
+	}
+	return o.Alpha
+}
+
+func (o DeltaOptions) resamples() int {
+	if o.Resamples <= 0 {
+		return DefaultDeltaResamples
+	}
+	return o.Resamples
+}
+
+// CreateKeySet returns the keys of m as a set.
+func CreateKeySet(m SurveyStatsMap) map[SurveyKey]struct{} {
+	set := make(map[SurveyKey]struct{}, len(m))
+	for key := range m {
+		set[key] = struct{}{}
+	}
+	return set
+}
+
+// CompareKeySets splits the keys of two sets into those in both, those only
+// in a and those only in b.
+func CompareKeySets(a, b map[SurveyKey]struct{}) (common, onlyA, onlyB map[SurveyKey]struct{}) {
+	common = make(map[SurveyKey]struct{})
+	onlyA = make(map[SurveyKey]struct{})
+	onlyB = make(map[SurveyKey]struct{})
+	for key := range a {
+		if _, ok := b[key]; ok {
+			common[key] = struct{}{}
+		} else {
+			onlyA[key] = struct{}{}
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			onlyB[key] = struct{}{}
+		}
+	}
+	return common, onlyA, onlyB
+}
+
+// CalculateDelta compares each metric reported in both a and b, the Stats of
+// one cell in two surveys, and stores the result in d. The comparison works
+// on Stats.Samples, floor readings taken at the floor value. Delta is the
+// Estimator of the chosen test on b minus that on a, negative through a wall
+// and positive with a booster, so that it, its bootstrap interval and the
+// PValue all describe the same difference; it is not the difference of the
+// Mean of the Stats when those are averaged otherwise. A metric with fewer
+// than two samples on either side is not tested and gets a PValue of 1. It
+// returns an error when the Samples of a metric do not match its Number.
+func (d SurveyDeltaStats) CalculateDelta(a, b SurveyStats, opts DeltaOptions) error {
+	alpha := opts.AlphaLevel()
+	for metric, statsA := range a {
+		statsB, ok := b[metric]
+		if !ok || statsA.Number == 0 || statsB.Number == 0 {
+			continue
+		}
+		if len(statsA.Samples) != int(statsA.Number) || len(statsB.Samples) != int(statsB.Number) {
+			return fmt.Errorf("%s: %d and %d samples kept for %d and %d measurements", metric, len(statsA.Samples), len(statsB.Samples), statsA.Number, statsB.Number)
+		}
+		valuesA, valuesB := observationValues(statsA.Samples), observationValues(statsB.Samples)
+		delta := DeltaStats{
+			Number1: statsA.Number,
+			Number2: statsB.Number,
+			Alpha:   alpha,
+			Test:    opts.Test,
+			Delta:   opts.Test.location(valuesB) - opts.Test.location(valuesA),
+			PValue:  1,
+			UPValue: 1,
+		}
+		if len(valuesA) > 1 && len(valuesB) > 1 {
+			delta.TTestValue, delta.TTestPValue, delta.EffectSizeR = welch(valuesA, valuesB)
+			delta.UValue, delta.UPValue = mannWhitney(valuesA, valuesB)
+			delta.CohensD = cohensD(valuesA, valuesB)
+			delta.CILow, delta.CIHigh = bootstrapDelta(valuesA, valuesB, opts.Test.location, opts.resamples(), 1-alpha)
+			delta.PValue = delta.TTestPValue
+			if opts.Test == MannWhitneyTest {
+				delta.PValue = delta.UPValue
+			}
+			delta.AreSignificantlyDiff = delta.PValue < alpha
+		}
+		d[metric] = delta
+	}
+	return nil
+}
+
+func observationValues(observations []Observation) []float64 {
+	values := make([]float64, len(observations))
+	for i, observation := range observations {
+		values[i] = observation.Value
+	}
+	return values
+}
+
+// welch returns the Welch t statistic of the mean of b minus that of a, its
+// two-sided p-value and the effect size r = t/√(t²+ν).
+func welch(a, b []float64) (t, p, r float64) {
+	meanA, varA := stat.MeanVariance(a, nil)
+	meanB, varB := stat.MeanVariance(b, nil)
+	nA, nB := float64(len(a)), float64(len(b))
+	seA, seB := varA/nA, varB/nB
+	if seA+seB == 0 {
+		if meanA == meanB {
+			return 0, 1, 0
+		}
+		return math.Copysign(math.Inf(1), meanB-meanA), 0, math.Copysign(1, meanB-meanA)
+	}
+	t = (meanB - meanA) / math.Sqrt(seA+seB)
+	// Welch–Satterthwaite degrees of freedom
+	df := (seA + seB) * (seA + seB) / (seA*seA/(nA-1) + seB*seB/(nB-1))
+	p = 2 * distuv.StudentsT{Mu: 0, Sigma: 1, Nu: df}.Survival(math.Abs(t))
+	r = t / math.Sqrt(t*t+df)
+	return t, p, r
+}
+
+// mannWhitney returns the U statistic of b against a and its two-sided
+// p-value, from the normal approximation with tie and continuity corrections.
+func mannWhitney(a, b []float64) (u, p float64) {
+	type ranked struct {
+		value float64
+		inB   bool
+	}
+	all := make([]ranked, 0, len(a)+len(b))
+	for _, value := range a {
+		all = append(all, ranked{value: value})
+	}
+	for _, value := range b {
+		all = append(all, ranked{value: value, inB: true})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	nA, nB := float64(len(a)), float64(len(b))
+	n := nA + nB
+	rankSumB, ties := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		// Tied values share the mean of their ranks, i+1 to j.
+		rank := float64(i+1+j) / 2
+		for k := i; k < j; k++ {
+			if all[k].inB {
+				rankSumB += rank
+			}
+		}
+		if size := float64(j - i); size > 1 {
+			ties += size*size*size - size
+		}
+		i = j
+	}
+	u = rankSumB - nB*(nB+1)/2
+
+	mean := nA * nB / 2
+	variance := nA * nB / 12 * ((n + 1) - ties/(n*(n-1)))
+	if variance <= 0 {
+		return u, 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	return u, 2 * distuv.UnitNormal.Survival(z)
+}
+
+// cohensD returns the difference of the means of b and a in units of their
+// pooled standard deviation.
+func cohensD(a, b []float64) float64 {
+	meanA, varA := stat.MeanVariance(a, nil)
+	meanB, varB := stat.MeanVariance(b, nil)
+	nA, nB := float64(len(a)), float64(len(b))
+	pooled := math.Sqrt(((nA-1)*varA + (nB-1)*varB) / (nA + nB - 2))
+	if pooled == 0 {
+		if meanA == meanB {
+			return 0
+		}
+		return math.Copysign(math.Inf(1), meanB-meanA)
+	}
+	return (meanB - meanA) / pooled
+}
+
+// bootstrapDelta is the percentile bootstrap interval of the location of b
+// minus that of a, each side resampled on its own.
+func bootstrapDelta(a, b []float64, location func([]float64) float64, resamples int, level float64) (float64, float64) {
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	resampleA := make([]float64, len(a))
+	resampleB := make([]float64, len(b))
+	deltas := make([]float64, resamples)
+	for i := range deltas {
+		for j := range resampleA {
+			resampleA[j] = a[rng.Intn(len(a))]
+		}
+		for j := range resampleB {
+			resampleB[j] = b[rng.Intn(len(b))]
+		}
+		deltas[i] = location(resampleB) - location(resampleA)
+	}
+	sort.Float64s(deltas)
+	tail := (1 - level) / 2
+	return stat.Quantile(tail, stat.LinInterp, deltas, nil), stat.Quantile(1-tail, stat.LinInterp, deltas, nil)
+}
@@ -0,0 +1,95 @@
+package lichens
+
+import "testing"
+
+func TestWelch(t *testing.T) {
+	a := []float64{1, 2, 3, 4, 5}
+	b := []float64{2, 4, 6, 8, 10}
+	// t = 3/√2.5, ν = 2.5²/(0.5²/4 + 2²/4) = 5.882
+	tValue, p, r := welch(a, b)
+	if !almostEqual(tValue, 1.8973665961, 1e-9) {
+		t.Errorf("t = %v, want 1.8973665961", tValue)
+	}
+	if !almostEqual(p, 0.1075311949, 1e-6) {
+		t.Errorf("p = %v, want 0.1075311949", p)
+	}
+	if !almostEqual(r, 0.6161595617, 1e-9) {
+		t.Errorf("r = %v, want 0.6161595617", r)
+	}
+}
+
+func TestMannWhitneyTies(t *testing.T) {
+	a := []float64{1, 2, 2, 3}
+	b := []float64{2, 3, 4, 5}
+	// Rank sum of b 3+5.5+7+8 = 23.5; tie term 3³-3 + 2³-2 = 30.
+	u, p := mannWhitney(a, b)
+	if u != 13.5 {
+		t.Errorf("U = %v, want 13.5", u)
+	}
+	if !almostEqual(p, 0.1366582477, 1e-9) {
+		t.Errorf("p = %v, want 0.1366582477", p)
+	}
+}
+
+func TestCohensD(t *testing.T) {
+	a := []float64{1, 2, 3, 4, 5}
+	b := []float64{2, 4, 6, 8, 10}
+	// Pooled SD √((4·2.5 + 4·10)/8) = 2.5
+	if d := cohensD(a, b); !almostEqual(d, 1.2, 1e-12) {
+		t.Errorf("d = %v, want 1.2", d)
+	}
+}
+
+func TestCalculateDelta(t *testing.T) {
+	a := SurveyStats{"DBM": {Number: 5, Mean: -80, Samples: observationsOf(-70, -72, -75, -90, -93)}}
+	b := SurveyStats{"DBM": {Number: 5, Mean: -95, Samples: observationsOf(-90, -91, -95, -99, -100)}}
+
+	// PValue is that of t.test(b, a) and wilcox.test(b, a, exact = FALSE) in
+	// R. CILow and CIHigh are the 2.5 and 97.5 % quantiles of the exact
+	// bootstrap distribution, enumerated over the 5⁵·5⁵ resamples, that the
+	// Monte Carlo bootstrap approximates.
+	tests := []struct {
+		test        DeltaTest
+		delta       float64
+		pValue      float64
+		low, high   float64
+		ciTolerance float64
+	}{
+		{WelchTest, -15, 0.0314594622, -23.8, -5.6, 1},
+		{MannWhitneyTest, -20, 0.0465329851, -28, 0, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.test.String(), func(t *testing.T) {
+			d := make(SurveyDeltaStats)
+			if err := d.CalculateDelta(a, b, DeltaOptions{Test: test.test}); err != nil {
+				t.Fatal(err)
+			}
+			delta := d["DBM"]
+			if delta.Delta != test.delta {
+				t.Errorf("Delta = %v, want %v", delta.Delta, test.delta)
+			}
+			if !almostEqual(delta.PValue, test.pValue, 1e-6) {
+				t.Errorf("PValue = %v, want %v", delta.PValue, test.pValue)
+			}
+			if !delta.AreSignificantlyDiff {
+				t.Errorf("not significantly different with PValue %v at Alpha %v", delta.PValue, delta.Alpha)
+			}
+			if !almostEqual(delta.CILow, test.low, test.ciTolerance) {
+				t.Errorf("CILow = %v, want %v ± %v", delta.CILow, test.low, test.ciTolerance)
+			}
+			if !almostEqual(delta.CIHigh, test.high, test.ciTolerance) {
+				t.Errorf("CIHigh = %v, want %v ± %v", delta.CIHigh, test.high, test.ciTolerance)
+			}
+			// r = t/√(t²+ν), t = -2.886751, ν = 5.387828
+			if !almostEqual(delta.EffectSizeR, -0.7793166401, 1e-9) {
+				t.Errorf("EffectSizeR = %v, want -0.7793166401", delta.EffectSizeR)
+			}
+		})
+	}
+
+	d := make(SurveyDeltaStats)
+	b["DBM"] = Stats{Number: 5, Mean: -95}
+	if err := d.CalculateDelta(a, b, DeltaOptions{}); err == nil {
+		t.Error("no error for Stats without Samples")
+	}
+}
@@ -0,0 +1,44 @@
+package lichens
+
+import (
+	"fmt"
+)
+
+// ParseMode selects how the reader reacts to malformed survey rows.
+type ParseMode int
+
+const (
+	// ParseStrict aborts the parse on the first malformed row.
+	ParseStrict ParseMode = iota
+	// ParseLenient skips malformed rows and records them as SurveyInfo.Warnings.
+	ParseLenient
+)
+
+// ParseError locates a value that could not be parsed.
+type ParseError struct {
+	File   string
+	Line   int
+	Column string
+	Value  string
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: column %s: invalid value %q: %v", e.File, e.Line, e.Column, e.Value, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseWarning is a row skipped in lenient mode.
+type ParseWarning struct {
+	ParseError
+}
+
+func (w ParseWarning) String() string {
+	return w.ParseError.Error()
+}
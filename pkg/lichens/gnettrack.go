@@ -0,0 +1,198 @@
+package lichens
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"time"
+)
+
+// G-NetTrack Pro log columns. Neighbour columns are numbered from 1, as in
+// "NTech1" or "NRxLev3".
+const (
+	gntTimestamp    = "Timestamp"
+	gntLongitude    = "Longitude"
+	gntLatitude     = "Latitude"
+	gntOperatorName = "Operatorname"
+	gntOperator     = "Operator"
+	gntCellID       = "CellID"
+	gntNode         = "Node"
+	gntLAC          = "LAC"
+	gntNetworkTech  = "NetworkTech"
+	gntLevel        = "Level"
+	gntQual         = "Qual"
+	gntSNR          = "SNR"
+	gntLTERSSI      = "LTERSSI"
+	gntARFCN        = "ARFCN"
+	gntPSC          = "PSC"
+	gntIMEI         = "IMEI"
+
+	gntNeighbourTech   = "NTech%d"
+	gntNeighbourCellID = "NCellid%d"
+	gntNeighbourPCI    = "NCell%d"
+	gntNeighbourARFCN  = "NARFCN%d"
+	gntNeighbourLevel  = "NRxLev%d"
+	gntNeighbourQual   = "NQual%d"
+
+	gntTimeLayout = "2006.01.02_15.04.05"
+)
+
+// GNetTrackReader reads the tab separated logs of the G-NetTrack Pro Android
+// app. Each log line becomes a round made of the serving cell and the
+// neighbours it lists. Level and Qual hold RSRP and RSRQ for LTE and NR, RSCP
+// and Ec/Io for UMTS and RxLev for GSM.
+//
+// Neighbours are only identified by their PCI or PSC; they get the CellID of
+// the serving cell seen on the same channel and PCI when the log has one, and
+// -PCI otherwise.
+type GNetTrackReader struct {
+	Options ParseOptions
+}
+
+// Sniff recognises the tab separated G-NetTrack header line.
+func (g GNetTrackReader) Sniff(head []byte) bool {
+	line, _, _ := bytes.Cut(head, []byte("\n"))
+	return bytes.HasPrefix(line, []byte(gntTimestamp+"\t")) && bytes.Contains(line, []byte("\t"+gntNetworkTech+"\t"))
+}
+
+func (g GNetTrackReader) WithOptions(opts ParseOptions) SurveyReader {
+	return GNetTrackReader{Options: opts}
+}
+
+func (g GNetTrackReader) Read(r io.Reader) (SurveyInfo, error) {
+	opts := g.Options
+	if opts.Name == "" {
+		opts.Name = "G-NetTrack log"
+	}
+	location := opts.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = '\t'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return SurveyInfo{}, fmt.Errorf("reading G-NetTrack header: %w", err)
+	}
+	cols := indexColumns(header)
+	for _, name := range []string{gntTimestamp, gntNetworkTech, gntLevel} {
+		if !cols.has(name) {
+			return SurveyInfo{}, &ParseError{File: opts.Name, Line: 1, Err: fmt.Errorf("G-NetTrack header is missing column %s", name)}
+		}
+	}
+
+	survey := SurveyInfo{
+		ApplicationVersion: "G-NetTrack Pro",
+		Filename:           path.Base(opts.Name),
+		Location:           location,
+		Surveys:            make(SurveyMap),
+	}
+
+	var cells []pendingCell
+	round := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var csvErr *csv.ParseError
+			if errors.As(err, &csvErr) {
+				return survey, &ParseError{File: opts.Name, Line: csvErr.Line, Err: csvErr.Err}
+			}
+			return survey, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := rowParser{record: record, cols: cols, file: opts.Name, line: line, layout: gntTimeLayout, location: location}
+		networkType, err := ParseGSMAType(row.string(gntNetworkTech))
+		if err != nil {
+			// No service or an unknown technology: nothing was measured.
+			continue
+		}
+		round++
+		rowCells := g.parseRow(&row, networkType.String(), round)
+		if row.err != nil {
+			if opts.Mode == ParseLenient {
+				survey.Warnings = append(survey.Warnings, ParseWarning{*row.err})
+				round--
+				continue
+			}
+			return survey, row.err
+		}
+		if survey.IMEINumber == "" {
+			survey.IMEINumber = row.string(gntIMEI)
+		}
+		cells = append(cells, rowCells...)
+	}
+	if len(cells) == 0 {
+		return survey, &ParseError{File: opts.Name, Err: errors.New("no measurement in G-NetTrack log")}
+	}
+
+	addCells(&survey, cells)
+	return survey, nil
+}
+
+// parseRow returns the serving cell of a log line followed by its neighbours.
+func (g GNetTrackReader) parseRow(row *rowParser, networkType string, round int) []pendingCell {
+	var serving SurveyData
+	serving.Survey = round
+	serving.Timestamp = row.time(gntTimestamp)
+	serving.Network = networkType
+	serving.Index = 1
+	serving.NetName = row.string(gntOperatorName)
+	serving.Latitude = row.optionalFloat(gntLatitude)
+	serving.Longitude = row.optionalFloat(gntLongitude)
+	if plmn := row.string(gntOperator); len(plmn) >= 5 {
+		serving.MCC, _ = strconv.Atoi(plmn[:3])
+		serving.MNC, _ = strconv.Atoi(plmn[3:])
+	}
+	serving.LACTAC = row.int(gntLAC)
+	serving.CellID = row.int(gntCellID)
+	if node := row.int(gntNode); networkType == "4G" && node > 0 && serving.CellID < 256 {
+		// G-NetTrack splits the E-UTRAN cell identity into eNB and cell.
+		serving.CellID = node<<8 | serving.CellID
+	}
+	serving.PCI = row.int(gntPSC)
+	serving.XRFCN = row.int(gntARFCN)
+	setLevels(&serving, row.optionalFloat(gntLevel), row.optionalFloat(gntQual))
+	serving.SINR = row.optionalFloat(gntSNR)
+	if rssi := row.optionalFloat(gntLTERSSI); rssi.Valid {
+		serving.DBM = rssi.Value
+	}
+	setBand(&serving)
+
+	cells := []pendingCell{{key: cellKey(serving), data: serving}}
+	for i := 1; row.cols.has(fmt.Sprintf(gntNeighbourTech, i)); i++ {
+		networkType, err := ParseGSMAType(row.string(fmt.Sprintf(gntNeighbourTech, i)))
+		if err != nil {
+			continue
+		}
+		neighbour := SurveyData{
+			Survey:    round,
+			Timestamp: serving.Timestamp,
+			Network:   networkType.String(),
+			Index:     len(cells) + 1,
+			NetName:   serving.NetName,
+			MCC:       serving.MCC,
+			MNC:       serving.MNC,
+			Latitude:  serving.Latitude,
+			Longitude: serving.Longitude,
+			CellID:    row.int(fmt.Sprintf(gntNeighbourCellID, i)),
+			PCI:       row.int(fmt.Sprintf(gntNeighbourPCI, i)),
+			XRFCN:     row.int(fmt.Sprintf(gntNeighbourARFCN, i)),
+		}
+		setLevels(&neighbour, row.optionalFloat(fmt.Sprintf(gntNeighbourLevel, i)), row.optionalFloat(fmt.Sprintf(gntNeighbourQual, i)))
+		setBand(&neighbour)
+		cells = append(cells, pendingCell{key: cellKey(neighbour), data: neighbour, neighbour: true})
+	}
+	return cells
+}
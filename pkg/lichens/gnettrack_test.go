@@ -0,0 +1,58 @@
+package lichens
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func readFixture(t *testing.T, reader SurveyReader, filename string) (SurveyInfo, error) {
+	t.Helper()
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	return reader.Read(file)
+}
+
+// testdata/gnettrack.txt has a serving cell with one neighbour on lines 2, 4
+// and 5, no service on line 3 and an invalid Level on line 4.
+func TestGNetTrackReader(t *testing.T) {
+	const fixture = "testdata/gnettrack.txt"
+
+	_, err := readFixture(t, GNetTrackReader{Options: ParseOptions{Name: "gnettrack.txt"}}, fixture)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 4 || parseErr.Column != gntLevel {
+		t.Errorf("strict error %v, want a ParseError on line 4 column Level", err)
+	}
+
+	survey, err := readFixture(t, GNetTrackReader{Options: ParseOptions{Mode: ParseLenient}}, fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(survey.Warnings) != 1 || survey.Warnings[0].Line != 4 {
+		t.Errorf("Warnings = %v, want one on line 4", survey.Warnings)
+	}
+	if survey.IMEINumber != "351626102376784" {
+		t.Errorf("IMEINumber = %q", survey.IMEINumber)
+	}
+	if len(survey.Rounds) != 2 {
+		t.Errorf("%d rounds, want 2", len(survey.Rounds))
+	}
+
+	// The eNB 77238 and cell 15 make the E-UTRAN cell 19772943; the neighbour
+	// is only known by its PCI.
+	serving := SurveyKey{Band: 2600, CellID: 19772943, NetName: "Orange", NetworkType: "4G"}
+	neighbour := SurveyKey{Band: 800, CellID: -322, NetName: "Orange", NetworkType: "4G"}
+	if len(survey.Surveys) != 2 || len(survey.Surveys[serving]) != 2 || len(survey.Surveys[neighbour]) != 2 {
+		t.Fatalf("Surveys = %v, want two samples of %v and %v", survey.Surveys, serving, neighbour)
+	}
+	first := survey.Surveys[serving][0]
+	if first.RSRP != ReportedFloat(-80) || first.RSRQ != ReportedFloat(-10) || first.DBM != -55 || first.PCI != 391 {
+		t.Errorf("serving cell RSRP %v RSRQ %v DBM %v PCI %d, want -80, -10, -55, 391", first.RSRP, first.RSRQ, first.DBM, first.PCI)
+	}
+	if level := survey.Surveys[neighbour][1].RSRP; level != ReportedFloat(-92) {
+		t.Errorf("neighbour RSRP %v, want -92", level)
+	}
+}
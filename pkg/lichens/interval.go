@@ -0,0 +1,103 @@
+package lichens
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// DefaultConfidence is the confidence level used when StatOptions leaves it
+// unset.
+const DefaultConfidence = 0.95
+
+// bootstrapSeed seeds the resampling so that a report can be reproduced.
+const bootstrapSeed = 1
+
+// ConfidenceLevel returns the confidence level of the intervals.
+func (o StatOptions) ConfidenceLevel() float64 {
+	if o.Confidence <= 0 || o.Confidence >= 1 {
+		return DefaultConfidence
+	}
+	return o.Confidence
+}
+
+// CIHalfWidth returns half the width of the confidence interval of Mean, +Inf
+// when it could not be estimated.
+func (s Stats) CIHalfWidth() float64 {
+	return (s.CIHigh - s.CILow) / 2
+}
+
+// estimate computes the Mean of observations as calculateMetrics does.
+func estimate(observations []Observation, metric string, mode AverageMode) Stats {
+	s := newCensoredStats(observations)
+	s.Mean = average(s, observations, metric, mode)
+	return s
+}
+
+// confidenceInterval returns the interval of the Mean of s at the confidence
+// level of opts: a percentile bootstrap of the estimator when opts.Bootstrap
+// resamples are asked for, a Student t interval otherwise. A single sample
+// gives an infinite interval, and floor readings only an upper bound.
+func confidenceInterval(s Stats, observations []Observation, metric string, opts StatOptions) (float64, float64) {
+	n := len(observations)
+	if n < 2 {
+		return math.Inf(-1), math.Inf(1)
+	}
+	if s.Censored == uint(n) {
+		return math.Inf(-1), s.Mean
+	}
+	level := opts.ConfidenceLevel()
+	if opts.Bootstrap > 0 {
+		return bootstrapInterval(observations, metric, opts.Average, opts.Bootstrap, level)
+	}
+	return tInterval(s, observations, metric, opts.Average, level)
+}
+
+// tInterval is the Student t interval of the mean. The median uses the
+// asymptotic standard error √(π/2)·σ/√n, and the linear average of uncensored
+// power levels the interval of the mean power in mW converted back to dBm.
+// Otherwise σ is that of the dB values, the Tobit estimate when censored.
+func tInterval(s Stats, observations []Observation, metric string, mode AverageMode, level float64) (float64, float64) {
+	n := float64(len(observations))
+	t := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: n - 1}.Quantile((1 + level) / 2)
+
+	if mode == AverageLinear && powerMetrics[metric] && s.Censored == 0 {
+		powers := make([]float64, len(observations))
+		for i, observation := range observations {
+			powers[i] = math.Pow(10, observation.Value/10)
+		}
+		mean, std := stat.MeanStdDev(powers, nil)
+		halfWidth := t * std / math.Sqrt(n)
+		low := math.Inf(-1)
+		if mean > halfWidth {
+			low = 10 * math.Log10(mean-halfWidth)
+		}
+		return low, 10 * math.Log10(mean+halfWidth)
+	}
+
+	standardError := s.StandardDeviation / math.Sqrt(n)
+	if mode == AverageMedian {
+		standardError *= math.Sqrt(math.Pi / 2)
+	}
+	return s.Mean - t*standardError, s.Mean + t*standardError
+}
+
+// bootstrapInterval is the percentile bootstrap interval of the Mean over the
+// given number of resamples.
+func bootstrapInterval(observations []Observation, metric string, mode AverageMode, resamples int, level float64) (float64, float64) {
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	resample := make([]Observation, len(observations))
+	means := make([]float64, resamples)
+	for i := range means {
+		for j := range resample {
+			resample[j] = observations[rng.Intn(len(observations))]
+		}
+		means[i] = estimate(resample, metric, mode).Mean
+	}
+	sort.Float64s(means)
+	alpha := (1 - level) / 2
+	return stat.Quantile(alpha, stat.LinInterp, means, nil), stat.Quantile(1-alpha, stat.LinInterp, means, nil)
+}
@@ -0,0 +1,100 @@
+package lichens
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConfidenceIntervalT(t *testing.T) {
+	// Mean -80 dBm, σ 10 dB, n 3: the 95 % quantile of Student's t with 2
+	// degrees of freedom is 4.302653, the half-width 4.302653·10/√3.
+	odd := observationsOf(-70, -80, -90)
+	tests := []struct {
+		name         string
+		observations []Observation
+		metric       string
+		opts         StatOptions
+		low, high    float64
+	}{
+		{"db", odd, "RSRP", StatOptions{}, -104.841377, -55.158623},
+		// The 90 % quantile with 2 degrees of freedom is 2.919986.
+		{"level", odd, "RSRP", StatOptions{Confidence: 0.9}, -96.858545, -63.141455},
+		// Median -80 dBm, half-width 24.841377·√(π/2).
+		{"median", odd, "RSRP", StatOptions{Average: AverageMedian}, -111.134049, -48.865951},
+		// Mean power of 10⁻⁶, 10⁻⁶·⁰⁵ and 10⁻⁶·¹ mW ± its t half-width, in dBm.
+		{"linear", observationsOf(-60, -60.5, -61), "RSRP", StatOptions{Average: AverageLinear}, -61.940957, -59.390031},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stats := estimate(test.observations, test.metric, test.opts.Average)
+			low, high := confidenceInterval(stats, test.observations, test.metric, test.opts)
+			if !almostEqual(low, test.low, 1e-5) || !almostEqual(high, test.high, 1e-5) {
+				t.Errorf("interval = [%v, %v], want [%v, %v]", low, high, test.low, test.high)
+			}
+		})
+	}
+}
+
+func TestConfidenceIntervalLinearUnbounded(t *testing.T) {
+	// The t half-width of 10⁻⁶, 10⁻⁹ and 10⁻⁷ mW exceeds their mean, so the
+	// interval has no lower bound in dBm.
+	observations := observationsOf(-60, -90, -70)
+	stats := estimate(observations, "RSRP", AverageLinear)
+	low, high := confidenceInterval(stats, observations, "RSRP", StatOptions{Average: AverageLinear})
+	if !math.IsInf(low, -1) || !almostEqual(high, -57.608683, 1e-5) {
+		t.Errorf("interval = [%v, %v], want [-Inf, -57.608683]", low, high)
+	}
+}
+
+func TestConfidenceIntervalBootstrap(t *testing.T) {
+	observations := observationsOf(-70, -72, -75, -80, -81, -85, -90, -93)
+	for _, mode := range []AverageMode{AverageDB, AverageLinear, AverageMedian} {
+		opts := StatOptions{Average: mode, Bootstrap: 2000}
+		stats := estimate(observations, "RSRP", mode)
+		low, high := confidenceInterval(stats, observations, "RSRP", opts)
+		if again, _ := confidenceInterval(stats, observations, "RSRP", opts); again != low {
+			t.Errorf("%s: bootstrap interval not reproducible, low %v then %v", mode, low, again)
+		}
+		if !(low < stats.Mean && stats.Mean < high) || low < -93 || high > -70 {
+			t.Errorf("%s: bootstrap interval [%v, %v] does not contain the average %v within the samples", mode, low, high, stats.Mean)
+		}
+	}
+}
+
+func TestConfidenceIntervalDegenerate(t *testing.T) {
+	single := observationsOf(-85)
+	low, high := confidenceInterval(estimate(single, "RSRP", AverageDB), single, "RSRP", StatOptions{})
+	if !math.IsInf(low, -1) || !math.IsInf(high, 1) {
+		t.Errorf("interval of one sample = [%v, %v], want [-Inf, +Inf]", low, high)
+	}
+
+	// Floor readings only bound the level from above.
+	floor := []Observation{{Value: FloorRSRP, Censored: true}, {Value: FloorRSRP, Censored: true}}
+	stats := estimate(floor, "RSRP", AverageDB)
+	low, high = confidenceInterval(stats, floor, "RSRP", StatOptions{})
+	if !math.IsInf(low, -1) || high != FloorRSRP {
+		t.Errorf("interval of floor readings = [%v, %v], want [-Inf, %v]", low, high, FloorRSRP)
+	}
+}
+
+func TestCICell(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats Stats
+		maxCI float64
+		want  string
+	}{
+		{"no samples", Stats{}, 3, "-"},
+		{"infinite", Stats{Number: 1, CILow: math.Inf(-1), CIHigh: math.Inf(1)}, 0, "∞"},
+		{"infinite flagged", Stats{Number: 1, CILow: math.Inf(-1), CIHigh: math.Inf(1)}, 3, "∞ *"},
+		{"upper bound", Stats{Number: 2, Censored: 2, CILow: math.Inf(-1), CIHigh: FloorRSRP}, 3, "∞ *"},
+		{"within", Stats{Number: 5, CILow: -84.5, CIHigh: -79.5}, 3, "2.5"},
+		{"wider", Stats{Number: 5, CILow: -84.5, CIHigh: -75.5}, 3, "4.5 *"},
+		{"no threshold", Stats{Number: 5, CILow: -84.5, CIHigh: -75.5}, 0, "4.5"},
+	}
+	for _, test := range tests {
+		if got := ciCell(test.stats, test.maxCI); got != test.want {
+			t.Errorf("%s: ciCell = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package lichens
 import (
 	"fmt"
 	"math"
+	"sort"
 )
 
 func NewSurveyStatsSummary(surveytype string) *SurveySummary {
@@ -39,33 +40,31 @@ func (fm *SurveyDeltaStatsSummary) Set(key SurveyKey, value SurveyDeltaStats) {
 	if value["DBM"].Delta < fm.Min {
 		fm.Min = value["DBM"].Delta
 	}
-	if value["RSSI"].Delta > fm.Max {
+	if value["DBM"].Delta > fm.Max {
 		fm.Max = value["DBM"].Delta
 	}
 }
 
-func SurveyStatGen(data SurveyInfo) SurveySummary {
+// SurveyStatGen computes the statistics of each cell of a survey with the
+// calculator registered for its network type, configured with opts. Cells of
+// a network type without a calculator are left out and listed in
+// SurveySummary.Unsupported.
+func SurveyStatGen(data SurveyInfo, opts StatOptions) SurveySummary {
 
 	result := NewSurveyStatsSummary(data.SurveyType)
+	result.Warnings = data.Warnings
+	result.Options = opts
 
 	for key, slice := range data.Surveys {
-
-		var stats map[string]Stats
-
-		switch key.NetworkType {
-		case "2G":
-			calculator := &TwoGCalculator{}
-			stats = calculator.Calculate(slice)
-		case "3G":
-			calculator := &ThreeGCalculator{}
-			stats = calculator.Calculate(slice)
-		case "4G":
-			calculator := &FourGCalculator{}
-			stats = calculator.Calculate(slice)
-		default:
-			// Handle default or unknown case, maybe log an error or return.
+		calculator, ok := Calculator(key.NetworkType)
+		if !ok {
+			result.Unsupported = append(result.Unsupported, key)
+			continue
 		}
-		result.Set(key, stats)
+		if configurable, ok := calculator.(ConfigurableCalculator); ok {
+			calculator = configurable.WithOptions(opts)
+		}
+		result.Set(key, calculator.Calculate(slice))
 	}
 	return *result
 }
@@ -87,3 +86,25 @@ func GetKeys[K SurveyKey, V any](m map[K]V) ([]K, error) {
 	}
 	return keys, nil
 }
+
+// NetworkTypes returns the network types present in the keys of m, ordered
+// from 2G to 5G, so that only the relevant reports are produced.
+func NetworkTypes[V any](m map[SurveyKey]V) []string {
+	seen := make(map[string]bool)
+	var types []string
+	for key := range m {
+		if !seen[key.NetworkType] {
+			seen[key.NetworkType] = true
+			types = append(types, key.NetworkType)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		ti, errI := ParseGSMAType(types[i])
+		tj, errJ := ParseGSMAType(types[j])
+		if errI != nil || errJ != nil || ti == tj {
+			return types[i] < types[j]
+		}
+		return ti < tj
+	})
+	return types
+}
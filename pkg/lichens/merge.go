@@ -0,0 +1,155 @@
+package lichens
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergeMode selects how Merge reacts to surveys taken by different devices,
+// firmwares or survey types.
+type MergeMode int
+
+const (
+	// MergeRefuse fails when the surveys differ.
+	MergeRefuse MergeMode = iota
+	// MergeWarn merges anyway and returns the differences.
+	MergeWarn
+)
+
+// MergeOptions configures Merge.
+type MergeOptions struct {
+	Mode MergeMode
+}
+
+// MergeConflict is a header field that differs between merged surveys.
+type MergeConflict struct {
+	Field  string
+	Values map[string]string // value by source name
+}
+
+func (c MergeConflict) Error() string {
+	names := SortedFilenames(c.Values)
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = fmt.Sprintf("%s=%q", name, c.Values[name])
+	}
+	return fmt.Sprintf("%s differs: %s", c.Field, strings.Join(values, ", "))
+}
+
+// SurveySource records which merged survey the rounds FirstRound to LastRound
+// come from.
+type SurveySource struct {
+	Name            string
+	IMEINumber      string
+	FirmwareVersion string
+	SurveyType      string
+	FileCreated     time.Time
+	FirstRound      int
+	LastRound       int
+}
+
+// Merge combines surveys of one location, typically the files of a GRAPHYTE
+// stopped and restarted on the spot, keyed by file name. The surveys are
+// taken in FileCreated order and their rounds renumbered to follow each other;
+// SurveyInfo.Sources keeps the round range of each file. The header comes from
+// the first survey and the type becomes "Full" when the types differ.
+//
+// Differences in IMEI, firmware version or survey type make Merge fail in
+// MergeRefuse mode; in MergeWarn mode they are returned with the merged
+// survey.
+func Merge(surveys map[string]SurveyInfo, opts MergeOptions) (SurveyInfo, []MergeConflict, error) {
+	names := SortedFilenames(surveys)
+	if len(names) == 0 {
+		return SurveyInfo{}, nil, errors.New("no surveys to merge")
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return surveys[names[i]].FileCreated.Before(surveys[names[j]].FileCreated)
+	})
+
+	conflicts := mergeConflicts(surveys, names)
+	if len(conflicts) > 0 && opts.Mode == MergeRefuse {
+		errs := make([]error, len(conflicts))
+		for i, conflict := range conflicts {
+			errs[i] = conflict
+		}
+		return SurveyInfo{}, conflicts, fmt.Errorf("surveys cannot be merged: %w", errors.Join(errs...))
+	}
+
+	merged := surveys[names[0]]
+	merged.Surveys = make(SurveyMap)
+	merged.Warnings = nil
+	merged.Sources = nil
+	round := 0
+	for _, name := range names {
+		survey := surveys[name]
+		if survey.SurveyType != merged.SurveyType {
+			merged.SurveyType = "Full"
+		}
+
+		rounds := survey.Rounds
+		if rounds == nil {
+			rounds = BuildRounds(survey.Surveys)
+		}
+		source := SurveySource{
+			Name:            name,
+			IMEINumber:      survey.IMEINumber,
+			FirmwareVersion: survey.FirmwareVersion,
+			SurveyType:      survey.SurveyType,
+			FileCreated:     survey.FileCreated,
+			FirstRound:      round + 1,
+		}
+		for _, r := range rounds {
+			round++
+			for _, cell := range r.Cells {
+				data := cell.Data
+				data.Survey = round
+				merged.Surveys[cell.Key] = append(merged.Surveys[cell.Key], data)
+			}
+		}
+		source.LastRound = round
+		merged.Sources = append(merged.Sources, source)
+		merged.Warnings = append(merged.Warnings, survey.Warnings...)
+	}
+	merged.Rounds = BuildRounds(merged.Surveys)
+	return merged, conflicts, nil
+}
+
+// mergeConflicts lists the header fields that differ between the surveys.
+func mergeConflicts(surveys map[string]SurveyInfo, names []string) []MergeConflict {
+	fields := []struct {
+		name  string
+		value func(SurveyInfo) string
+	}{
+		{"IMEI Number", func(s SurveyInfo) string { return s.IMEINumber }},
+		{"Firmware Version", func(s SurveyInfo) string { return s.FirmwareVersion }},
+		{"Survey Type", func(s SurveyInfo) string { return s.SurveyType }},
+	}
+
+	var conflicts []MergeConflict
+	for _, field := range fields {
+		values := make(map[string]string, len(names))
+		distinct := make(map[string]bool)
+		for _, name := range names {
+			value := field.value(surveys[name])
+			values[name] = value
+			distinct[value] = true
+		}
+		if len(distinct) > 1 {
+			conflicts = append(conflicts, MergeConflict{Field: field.name, Values: values})
+		}
+	}
+	return conflicts
+}
+
+// Source returns the merged survey that round comes from.
+func (s SurveyInfo) Source(round int) (SurveySource, bool) {
+	for _, source := range s.Sources {
+		if round >= source.FirstRound && round <= source.LastRound {
+			return source, true
+		}
+	}
+	return SurveySource{}, false
+}
@@ -0,0 +1,96 @@
+package lichens
+
+import (
+	"os"
+	"testing"
+)
+
+func readTestSurvey(t *testing.T, filename string) SurveyInfo {
+	t.Helper()
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	survey, err := ParseSurvey(file, ParseOptions{Name: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return survey
+}
+
+func sampleCount(s SurveyInfo) int {
+	n := 0
+	for _, slice := range s.Surveys {
+		n += len(slice)
+	}
+	return n
+}
+
+func TestMerge(t *testing.T) {
+	first := readTestSurvey(t, "../../L3240918.CSV")
+	second := readTestSurvey(t, "../../L3241030.CSV")
+	// The names sort against the FileCreated order.
+	surveys := map[string]SurveyInfo{"b-morning.csv": first, "a-noon.csv": second}
+
+	merged, conflicts, err := Merge(surveys, MergeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("conflicts %v between surveys of one device", conflicts)
+	}
+	if got, want := sampleCount(merged), sampleCount(first)+sampleCount(second); got != want {
+		t.Errorf("%d samples merged, want %d", got, want)
+	}
+	if got, want := len(merged.Rounds), len(first.Rounds)+len(second.Rounds); got != want {
+		t.Fatalf("%d rounds merged, want %d", got, want)
+	}
+	for i, round := range merged.Rounds {
+		if round.Number != i+1 {
+			t.Fatalf("round %d numbered %d", i+1, round.Number)
+		}
+		if i > 0 && round.Start.Before(merged.Rounds[i-1].Start) {
+			t.Errorf("round %d starts at %v, before round %d", round.Number, round.Start, i)
+		}
+	}
+
+	last := len(first.Rounds)
+	for _, test := range []struct {
+		round int
+		name  string
+	}{
+		{1, "b-morning.csv"},
+		{last, "b-morning.csv"},
+		{last + 1, "a-noon.csv"},
+		{len(merged.Rounds), "a-noon.csv"},
+	} {
+		if source, ok := merged.Source(test.round); !ok || source.Name != test.name {
+			t.Errorf("round %d comes from %q, want %q", test.round, source.Name, test.name)
+		}
+	}
+	if !merged.FileCreated.Equal(first.FileCreated) {
+		t.Errorf("FileCreated = %v, want that of the first survey", merged.FileCreated)
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	first := readTestSurvey(t, "../../L3240918.CSV")
+	second := readTestSurvey(t, "../../L3241030.CSV")
+	second.IMEINumber = "'000000000000000"
+	surveys := map[string]SurveyInfo{"first.csv": first, "second.csv": second}
+
+	if _, conflicts, err := Merge(surveys, MergeOptions{Mode: MergeRefuse}); err == nil || len(conflicts) != 1 {
+		t.Errorf("refuse mode merged surveys of two devices: %v, %v", conflicts, err)
+	}
+	merged, conflicts, err := Merge(surveys, MergeOptions{Mode: MergeWarn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Field != "IMEI Number" {
+		t.Errorf("conflicts = %v, want the IMEI Number", conflicts)
+	}
+	if got, want := sampleCount(merged), sampleCount(first)+sampleCount(second); got != want {
+		t.Errorf("%d samples merged, want %d", got, want)
+	}
+}
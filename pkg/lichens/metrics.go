@@ -0,0 +1,36 @@
+package lichens
+
+// Metric returns the value of a statistics metric ("DBM", "RSSI", "RSCP",
+// "ECIO", "RSRP", "RSRQ" or "SINR") and whether the row reported it.
+func (d SurveyData) Metric(name string) (float64, bool) {
+	var value OptionalFloat
+	switch name {
+	case "DBM":
+		value = ReportedFloat(d.DBM)
+	case "RSSI":
+		value = d.RSSI
+	case "RSCP":
+		value = d.RSCP
+	case "ECIO":
+		value = d.ECIO
+	case "RSRP":
+		value = d.RSRP
+	case "RSRQ":
+		value = d.RSRQ
+	case "SINR":
+		value = d.SINR
+	}
+	return value.Value, value.Valid
+}
+
+// Values returns the reported values of a metric across the slice. Rows that
+// did not report it are skipped rather than counted as zero.
+func (s SurveyDataSlice) Values(metric string) []float64 {
+	values := make([]float64, 0, len(s))
+	for _, data := range s {
+		if value, ok := data.Metric(metric); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,91 @@
+package lichens
+
+// pendingCell is a parsed measurement waiting for neighbour resolution.
+type pendingCell struct {
+	key       SurveyKey
+	data      SurveyData
+	neighbour bool
+}
+
+// setLevels stores the level and quality of a cell in the fields of its
+// technology. DBM falls back to the level when no wideband power is known.
+func setLevels(data *SurveyData, level, quality OptionalFloat) {
+	switch data.Network {
+	case "2G":
+		data.DBM = level.Value
+	case "3G":
+		data.RSCP, data.ECIO = level, quality
+		data.DBM = level.Value
+	default:
+		data.RSRP, data.RSRQ = level, quality
+		data.DBM = level.Value
+	}
+}
+
+// setBand derives the band and downlink frequency from the channel number.
+func setBand(data *SurveyData) {
+	switch data.Network {
+	case "4G":
+		if band, frequency, dl, ok := earfcnBand(data.XRFCN); ok {
+			data.BandNum, data.Band, data.DL = band, frequency, dl
+		}
+	case "5G":
+		if band, frequency, ok := nrARFCNBand(data.XRFCN); ok {
+			data.BandNum, data.Band = band, frequency
+			data.DL = NRARFCNFrequency(data.XRFCN)
+		}
+	default:
+		data.Band = legacyBand(data.Network, data.XRFCN)
+	}
+}
+
+func cellKey(data SurveyData) SurveyKey {
+	return SurveyKey{Band: data.Band, CellID: data.CellID, NetName: data.NetName, NetworkType: data.Network}
+}
+
+// resolveNeighbours gives neighbours without a cell identity the CellID of the
+// serving cell seen on the same channel with the same PCI, or -PCI.
+func resolveNeighbours(cells []pendingCell) {
+	type channel struct {
+		netName     string
+		networkType string
+		xrfcn       int
+		pci         int
+	}
+	known := make(map[channel]int)
+	for _, cell := range cells {
+		if !cell.neighbour && cell.data.CellID != 0 {
+			known[channel{cell.data.NetName, cell.data.Network, cell.data.XRFCN, cell.data.PCI}] = cell.data.CellID
+		}
+	}
+	for i := range cells {
+		cell := &cells[i]
+		if !cell.neighbour || cell.data.CellID != 0 {
+			continue
+		}
+		if cellID, ok := known[channel{cell.data.NetName, cell.data.Network, cell.data.XRFCN, cell.data.PCI}]; ok {
+			cell.data.CellID = cellID
+		} else {
+			cell.data.CellID = -cell.data.PCI
+		}
+		cell.key = cellKey(cell.data)
+	}
+}
+
+// addCells resolves the neighbours of cells and stores them in the survey,
+// which gets its type, creation time and rounds from them.
+func addCells(survey *SurveyInfo, cells []pendingCell) {
+	resolveNeighbours(cells)
+
+	types := make(map[string]bool)
+	survey.FileCreated = cells[0].data.Timestamp
+	for _, cell := range cells {
+		survey.Surveys[cell.key] = append(survey.Surveys[cell.key], cell.data)
+		types[cell.key.NetworkType] = true
+	}
+	survey.SurveyType = "Full"
+	if len(types) == 1 {
+		survey.SurveyType = cells[0].key.NetworkType
+	}
+	survey.Rounds = BuildRounds(survey.Surveys)
+}
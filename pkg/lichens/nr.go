@@ -0,0 +1,45 @@
+package lichens
+
+import (
+	"strings"
+)
+
+// nrBandFrequency gives the nominal frequency in MHz of the common NR bands,
+// used as the survey key band when a row only names the band.
+var nrBandFrequency = map[int]int{
+	1:   2100,
+	3:   1800,
+	7:   2600,
+	8:   900,
+	20:  800,
+	28:  700,
+	38:  2600,
+	40:  2300,
+	41:  2500,
+	77:  3700,
+	78:  3500,
+	79:  4700,
+	257: 28000,
+	258: 26000,
+}
+
+// isNRBand reports whether s is an NR band name such as "n78".
+func isNRBand(s string) bool {
+	if len(s) < 2 || (s[0] != 'n' && s[0] != 'N') {
+		return false
+	}
+	return strings.Trim(s[1:], "0123456789") == ""
+}
+
+// NRARFCNFrequency converts an NR-ARFCN to its frequency in MHz, following
+// the global frequency raster of 3GPP TS 38.104.
+func NRARFCNFrequency(arfcn int) float64 {
+	switch {
+	case arfcn < 600000:
+		return 0.005 * float64(arfcn)
+	case arfcn < 2016667:
+		return 3000 + 0.015*float64(arfcn-600000)
+	default:
+		return 24250.08 + 0.06*float64(arfcn-2016667)
+	}
+}
@@ -0,0 +1,233 @@
+package lichens
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PercentileRanks are the ranks, in percent, of Stats.Percentiles.
+var PercentileRanks = [7]float64{5, 10, 25, 50, 75, 90, 95}
+
+// Percentile returns the percentile of the given rank, which must be one of
+// PercentileRanks. A coverage of "RSRP ≥ -105 dBm 95 % of the time" holds
+// when Percentile(5) is at least -105. Floor readings are counted at the
+// floor, so a percentile at the floor means at or below it.
+func (s Stats) Percentile(rank float64) (float64, bool) {
+	for i, r := range PercentileRanks {
+		if r == rank {
+			return s.Percentiles[i], true
+		}
+	}
+	return 0, false
+}
+
+// IQR returns the interquartile range.
+func (s Stats) IQR() float64 {
+	return s.Quartiles[2] - s.Quartiles[0]
+}
+
+// GroupLevel selects the grouping of the cells of a survey.
+type GroupLevel int
+
+const (
+	// GroupCell keeps each cell on its own.
+	GroupCell GroupLevel = iota
+	// GroupBand pools the cells of an operator on a band, CellID 0.
+	GroupBand
+	// GroupOperator pools the cells of an operator, Band and CellID 0.
+	GroupOperator
+)
+
+// ParseGroupLevel converts "cell", "band" or "operator" to a GroupLevel.
+func ParseGroupLevel(s string) (GroupLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "cell":
+		return GroupCell, nil
+	case "band":
+		return GroupBand, nil
+	case "operator", "mno":
+		return GroupOperator, nil
+	default:
+		return GroupCell, fmt.Errorf("unknown group %q, expected cell, band or operator", s)
+	}
+}
+
+func (l GroupLevel) String() string {
+	switch l {
+	case GroupBand:
+		return "band"
+	case GroupOperator:
+		return "operator"
+	default:
+		return "cell"
+	}
+}
+
+// GroupSurveys pools the samples of the cells of m at the given level. The
+// pooled keys follow SurveyKey, 0 standing for all bands or cells; the network
+// types are never pooled.
+func GroupSurveys(m SurveyMap, level GroupLevel) SurveyMap {
+	if level == GroupCell {
+		return m
+	}
+	grouped := make(SurveyMap)
+	for key, slice := range m {
+		key.CellID = 0
+		if level == GroupOperator {
+			key.Band = 0
+		}
+		grouped[key] = append(grouped[key], slice...)
+	}
+	return grouped
+}
+
+// ECDFPoint is a step of an empirical CDF: Fraction of the samples are at or
+// below Value.
+type ECDFPoint struct {
+	Value    float64 `json:"value"`
+	Fraction float64 `json:"fraction"`
+}
+
+// ECDF is the empirical distribution of a metric of a cell, or of the cells
+// pooled under Key. Censored counts the floor readings, included at the floor
+// value.
+type ECDF struct {
+	Key         SurveyKey
+	Metric      string
+	Number      int
+	Censored    int
+	Percentiles [7]float64
+	Points      []ECDFPoint
+}
+
+// NewECDF computes the empirical CDF of observations, one point per distinct
+// value.
+func NewECDF(key SurveyKey, metric string, observations []Observation) ECDF {
+	e := ECDF{Key: key, Metric: metric, Number: len(observations)}
+	values := make([]float64, len(observations))
+	for i, observation := range observations {
+		values[i] = observation.Value
+		if observation.Censored {
+			e.Censored++
+		}
+	}
+	e.Percentiles = newStats(values).Percentiles
+	sort.Float64s(values)
+	for i, value := range values {
+		fraction := float64(i+1) / float64(len(values))
+		if n := len(e.Points); n > 0 && e.Points[n-1].Value == value {
+			e.Points[n-1].Fraction = fraction
+			continue
+		}
+		e.Points = append(e.Points, ECDFPoint{Value: value, Fraction: fraction})
+	}
+	return e
+}
+
+// At returns the fraction of the samples at or below value.
+func (e ECDF) At(value float64) float64 {
+	i := sort.Search(len(e.Points), func(i int) bool { return e.Points[i].Value > value })
+	if i == 0 {
+		return 0
+	}
+	return e.Points[i-1].Fraction
+}
+
+// Above returns the fraction of the samples at or above value, the coverage
+// of a level threshold.
+func (e ECDF) Above(value float64) float64 {
+	i := sort.Search(len(e.Points), func(i int) bool { return e.Points[i].Value >= value })
+	if i == 0 {
+		return 1
+	}
+	return 1 - e.Points[i-1].Fraction
+}
+
+// SurveyECDF computes the ECDF of a metric for each key of m that reported
+// it, ordered by network type, operator, band and cell.
+func SurveyECDF(m SurveyMap, metric string) []ECDF {
+	var result []ECDF
+	for key, slice := range m {
+		observations := slice.Observations(metric)
+		if len(observations) == 0 {
+			continue
+		}
+		result = append(result, NewECDF(key, metric, observations))
+	}
+	sort.Slice(result, func(i, j int) bool { return keyLess(result[i].Key, result[j].Key) })
+	return result
+}
+
+// ecdfJSON is the JSON form of an ECDF.
+type ecdfJSON struct {
+	NetworkType string             `json:"network_type"`
+	Operator    string             `json:"operator"`
+	Band        int                `json:"band"`
+	CellID      int                `json:"cell_id"`
+	Metric      string             `json:"metric"`
+	Number      int                `json:"number"`
+	Censored    int                `json:"censored"`
+	Percentiles map[string]float64 `json:"percentiles"`
+	Points      []ECDFPoint        `json:"points"`
+}
+
+// WriteECDFJSON writes the distributions as a JSON array, percentiles keyed
+// "P5" to "P95".
+func WriteECDFJSON(w io.Writer, distributions []ECDF) error {
+	out := make([]ecdfJSON, 0, len(distributions))
+	for _, e := range distributions {
+		percentiles := make(map[string]float64, len(PercentileRanks))
+		for i, rank := range PercentileRanks {
+			percentiles[percentileLabel(rank)] = e.Percentiles[i]
+		}
+		out = append(out, ecdfJSON{
+			NetworkType: e.Key.NetworkType,
+			Operator:    e.Key.NetName,
+			Band:        e.Key.Band,
+			CellID:      e.Key.CellID,
+			Metric:      e.Metric,
+			Number:      e.Number,
+			Censored:    e.Censored,
+			Percentiles: percentiles,
+			Points:      e.Points,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// WriteECDFCSV writes the distributions in long form, one row per point.
+func WriteECDFCSV(w io.Writer, distributions []ECDF) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"GSMA", "MNO", "BAND", "CELLID", "METRIC", "VALUE", "FRACTION"}); err != nil {
+		return err
+	}
+	for _, e := range distributions {
+		for _, point := range e.Points {
+			record := []string{
+				e.Key.NetworkType,
+				e.Key.NetName,
+				strconv.Itoa(e.Key.Band),
+				strconv.Itoa(e.Key.CellID),
+				e.Metric,
+				strconv.FormatFloat(point.Value, 'f', -1, 64),
+				strconv.FormatFloat(point.Fraction, 'f', 6, 64),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func percentileLabel(rank float64) string {
+	return "P" + strconv.FormatFloat(rank, 'f', -1, 64)
+}
@@ -0,0 +1,50 @@
+package lichens
+
+import "testing"
+
+func TestECDF(t *testing.T) {
+	observations := []Observation{{Value: 2}, {Value: 1}, {Value: 3}, {Value: 2, Censored: true}}
+	e := NewECDF(SurveyKey{}, "DBM", observations)
+	if e.Number != 4 || e.Censored != 1 {
+		t.Errorf("Number, Censored = %d, %d, want 4, 1", e.Number, e.Censored)
+	}
+	want := []ECDFPoint{{1, 0.25}, {2, 0.75}, {3, 1}}
+	if len(e.Points) != len(want) {
+		t.Fatalf("Points = %v, want %v", e.Points, want)
+	}
+	for i := range want {
+		if e.Points[i] != want[i] {
+			t.Errorf("Points[%d] = %v, want %v", i, e.Points[i], want[i])
+		}
+	}
+
+	for _, test := range []struct {
+		value, at, above float64
+	}{
+		{0, 0, 1},
+		{1, 0.25, 1},
+		{2, 0.75, 0.75},
+		{2.5, 0.75, 0.25},
+		{3, 1, 0.25},
+		{4, 1, 0},
+	} {
+		if got := e.At(test.value); got != test.at {
+			t.Errorf("At(%v) = %v, want %v", test.value, got, test.at)
+		}
+		if got := e.Above(test.value); got != test.above {
+			t.Errorf("Above(%v) = %v, want %v", test.value, got, test.above)
+		}
+	}
+}
+
+func TestECDFPercentiles(t *testing.T) {
+	var observations []Observation
+	for value := 1.0; value <= 10; value++ {
+		observations = append(observations, Observation{Value: value})
+	}
+	// Linear interpolation of the empirical CDF, Hyndman and Fan type 4.
+	want := [7]float64{1, 1, 2.5, 5, 7.5, 9, 9.5}
+	if got := NewECDF(SurveyKey{}, "DBM", observations).Percentiles; got != want {
+		t.Errorf("Percentiles = %v, want %v", got, want)
+	}
+}
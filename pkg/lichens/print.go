@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"sort"
+	"strings"
 )
 
 func TablePrintALL(title string, surveySummary SurveySummary, primarySortColumn string) error {
@@ -41,11 +42,12 @@ func TablePrintALL(title string, surveySummary SurveySummary, primarySortColumn
 	})
 
 	tableWriter := table.NewWriter()
-	tableWriter.SetTitle(title + " " + surveySummary.SurveyType + " ALL BAND \n " + fmt.Sprint("DBM Min: ", int(surveySummary.Min), " Max: ", int(surveySummary.Max)))
+	tableWriter.SetTitle(title + " " + surveySummary.SurveyType + " ALL BAND, " + surveySummary.Options.Average.Title() + " \n " + fmt.Sprint("DBM Min: ", int(surveySummary.Min), " Max: ", int(surveySummary.Max)))
 	tableWriter.SetAutoIndex(true)
 	tableWriter.SetOutputMirror(os.Stdout)
 
-	tableWriter.AppendHeader(table.Row{"GSMA", "BAND", "MNO", "CellID", "DBM"})
+	tableWriter.AppendHeader(table.Row{"GSMA", "BAND", "MNO", "CellID", "DBM", "±"})
+	tableWriter.SetCaption("%s", ciCaption(surveySummary.Options))
 
 	for _, key := range keys {
 
@@ -66,6 +68,7 @@ func TablePrintALL(title string, surveySummary SurveySummary, primarySortColumn
 				color.Sprint(key.NetName),
 				color.Sprint(key.CellID),
 				color.Sprint(dbmValue),
+				color.Sprint(ciCell(dbm, surveySummary.Options.MaxCI)),
 			}
 			tableWriter.AppendRow(row)
 		}
@@ -76,6 +79,99 @@ func TablePrintALL(title string, surveySummary SurveySummary, primarySortColumn
 	return nil
 }
 
+// PrintParseWarnings lists the rows skipped by a lenient parse, followed by
+// their count.
+func PrintParseWarnings(warnings []ParseWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+	for _, warning := range warnings {
+		fmt.Println("warning:", warning)
+	}
+	fmt.Printf("%d malformed rows skipped\n", len(warnings))
+}
+
+// PrintUnsupported reports the cells left out of the statistics because no
+// MetricCalculator is registered for their network type.
+func PrintUnsupported(keys []SurveyKey) {
+	counts := make(map[string]int)
+	for _, key := range keys {
+		counts[key.NetworkType]++
+	}
+	for _, networkType := range SortedFilenames(counts) {
+		fmt.Printf("no calculator for network type %q: %d cells skipped\n", networkType, counts[networkType])
+	}
+}
+
+// TablePrintOperatorOverview prints one row per operator with, for each network
+// type present, the number of cells seen, the best cell DBM and the average DBM
+// of the cells weighted by their number of samples.
+func TablePrintOperatorOverview(title string, surveySummary SurveySummary) error {
+	if surveySummary.Stat == nil {
+		return fmt.Errorf("invalid surveySummary provided")
+	}
+	networkTypes := NetworkTypes(surveySummary.Stat)
+
+	type ratOverview struct {
+		cells   int
+		samples uint
+		best    float64
+		sum     float64
+	}
+	overview := make(map[string]map[string]*ratOverview)
+	var operators []string
+	for key, stat := range surveySummary.Stat {
+		if _, ok := overview[key.NetName]; !ok {
+			overview[key.NetName] = make(map[string]*ratOverview)
+			operators = append(operators, key.NetName)
+		}
+		rat, ok := overview[key.NetName][key.NetworkType]
+		if !ok {
+			rat = &ratOverview{best: -math.MaxFloat64}
+			overview[key.NetName][key.NetworkType] = rat
+		}
+		dbm := stat["DBM"]
+		rat.cells++
+		rat.samples += dbm.Number
+		rat.sum += dbm.Mean * float64(dbm.Number)
+		if dbm.Mean > rat.best {
+			rat.best = dbm.Mean
+		}
+	}
+	sort.Strings(operators)
+
+	tableWriter := table.NewWriter()
+	tableWriter.SetTitle(title + " " + surveySummary.SurveyType + " Operators, " + surveySummary.Options.Average.Title())
+	tableWriter.SetAutoIndex(true)
+	tableWriter.SetOutputMirror(os.Stdout)
+
+	header := table.Row{"MNO"}
+	for _, networkType := range networkTypes {
+		header = append(header, networkType+" CELLS", "#", "BEST DBM", "AVG DBM")
+	}
+	tableWriter.AppendHeader(header)
+
+	for _, operator := range operators {
+		row := table.Row{operator}
+		for _, networkType := range networkTypes {
+			rat, ok := overview[operator][networkType]
+			if !ok {
+				row = append(row, 0, 0, "-", "-")
+				continue
+			}
+			var average interface{} = "-"
+			if rat.samples > 0 {
+				average = roundTo2DP(rat.sum / float64(rat.samples))
+			}
+			row = append(row, rat.cells, rat.samples, roundTo2DP(rat.best), average)
+		}
+		tableWriter.AppendRow(row)
+	}
+
+	tableWriter.Render()
+	return nil
+}
+
 func contains(s []string, e string) bool {
 	for _, a := range s {
 		if a == e {
@@ -116,7 +212,7 @@ func PrintDeltaStatsTable(title string, freq bool, surveySummary SurveyDeltaStat
 	}
 
 	tableWriter := table.NewWriter()
-	tableWriter.SetTitle(title + " " + surveySummary.SurveyType + " " + " Stats " + fmt.Sprintf(" - Delta DBM Min: %d Max: %d", int(surveySummary.Min), int(surveySummary.Max)))
+	tableWriter.SetTitle(title + " " + surveySummary.SurveyType + " " + " Stats, delta of the " + surveySummary.Options.Test.Estimator() + "s" + fmt.Sprintf(" - Delta DBM Min: %d Max: %d", int(surveySummary.Min), int(surveySummary.Max)))
 	tableWriter.SetAutoIndex(true)
 	tableWriter.SetOutputMirror(os.Stdout)
 
@@ -138,11 +234,17 @@ func PrintDeltaStatsTable(title string, freq bool, surveySummary SurveyDeltaStat
 		return fmt.Errorf("error getting keys: %v", err)
 	}
 
+	// The level compared: RSRP where the network type reports it.
+	level := "RSRP"
+	if networkType == "2G" || networkType == "3G" {
+		level = "DBM"
+	}
+
 	sort.Slice(keys, func(i, j int) bool {
 		isIndoorBooster := surveySummary.DeltaType == IndoorBooster
 
-		rsrpDeltaI := surveySummary.DeltaStats[keys[i]]["RSRP"].Delta
-		rsrpDeltaJ := surveySummary.DeltaStats[keys[j]]["RSRP"].Delta
+		rsrpDeltaI := surveySummary.DeltaStats[keys[i]][level].Delta
+		rsrpDeltaJ := surveySummary.DeltaStats[keys[j]][level].Delta
 
 		// Prioritize sorting by DELTA RSRP
 		if rsrpDeltaI != rsrpDeltaJ {
@@ -178,18 +280,23 @@ func PrintDeltaStatsTable(title string, freq bool, surveySummary SurveyDeltaStat
 	var header table.Row
 	switch networkType {
 	case "2G", "3G":
-		header = table.Row{"GSMA", "BAND", "MNO", "CellID", "#1", "#2", "DELTA", "DIFFERENT"}
-	case "4G":
-		header = table.Row{"GSMA", "BAND", "MNO", "CellID", "#1", "#2", "DELTA RSRP", "DIFFERENT", "DELTA RSRQ", "DIFFERENT"}
+		header = table.Row{"GSMA", "BAND", "MNO", "CellID", "#1", "#2", "DELTA", "CI", "P", "D", "DIFFERENT"}
+	case "4G", "5G":
+		header = table.Row{"GSMA", "BAND", "MNO", "CellID", "#1", "#2", "DELTA RSRP", "CI", "P", "D", "DIFFERENT", "DELTA RSRQ", "DIFFERENT"}
 	}
 
 	tableWriter.AppendHeader(header)
+	tableWriter.SetCaption("%s", deltaCaption(surveySummary.Options))
 
 	for _, key := range keys {
 		count1 := surveySummary.DeltaStats[key]["DBM"].Number1
 		count2 := surveySummary.DeltaStats[key]["DBM"].Number2
-		differentRsrp := surveySummary.DeltaStats[key]["RSRP"].AreSignificantlyDiff
-		Value1 := roundTo2DP(surveySummary.DeltaStats[key]["RSRP"].Delta)
+		levelDelta := surveySummary.DeltaStats[key][level]
+		differentRsrp := levelDelta.AreSignificantlyDiff
+		Value1 := roundTo2DP(levelDelta.Delta)
+		interval := fmt.Sprintf("[%g, %g]", roundTo2DP(levelDelta.CILow), roundTo2DP(levelDelta.CIHigh))
+		pValue := fmt.Sprintf("%.3g", levelDelta.PValue)
+		cohensD := roundTo2DP(levelDelta.CohensD)
 
 		dbmValue := roundTo2DP(surveySummary.DeltaStats[key]["RSSI"].Delta)
 		color := getColorCoding(int(dbmValue), int(surveySummary.Min), int(surveySummary.Max))
@@ -197,7 +304,6 @@ func PrintDeltaStatsTable(title string, freq bool, surveySummary SurveyDeltaStat
 		var row table.Row
 		switch networkType {
 		case "2G", "3G":
-			different := surveySummary.DeltaStats[key]["DBM"].AreSignificantlyDiff
 			row = table.Row{
 				color.Sprint(key.NetworkType),
 				color.Sprint(key.Band),
@@ -206,9 +312,12 @@ func PrintDeltaStatsTable(title string, freq bool, surveySummary SurveyDeltaStat
 				color.Sprint(count1),
 				color.Sprint(count2),
 				color.Sprint(Value1),
-				color.Sprint(different),
+				color.Sprint(interval),
+				color.Sprint(pValue),
+				color.Sprint(cohensD),
+				color.Sprint(differentRsrp),
 			}
-		case "4G":
+		case "4G", "5G":
 			differentRsrq := surveySummary.DeltaStats[key]["RSRQ"].AreSignificantlyDiff
 			Value2 := roundTo2DP(surveySummary.DeltaStats[key]["RSRQ"].Delta)
 			row = table.Row{
@@ -219,6 +328,9 @@ func PrintDeltaStatsTable(title string, freq bool, surveySummary SurveyDeltaStat
 				color.Sprint(count1),
 				color.Sprint(count2),
 				color.Sprint(Value1),
+				color.Sprint(interval),
+				color.Sprint(pValue),
+				color.Sprint(cohensD),
 				color.Sprint(differentRsrp),
 				color.Sprint(Value2),
 				color.Sprint(differentRsrq),
@@ -239,7 +351,7 @@ func TablePrintStats(title string, freq bool, surveySummary SurveySummary, netwo
 	}
 
 	tableWriter := table.NewWriter()
-	tableWriter.SetTitle(title + " " + surveySummary.SurveyType + " " + networkType + " Stats")
+	tableWriter.SetTitle(title + " " + surveySummary.SurveyType + " " + networkType + " Stats, " + surveySummary.Options.Average.Title())
 	tableWriter.SetAutoIndex(true)
 	tableWriter.SetOutputMirror(os.Stdout)
 
@@ -256,15 +368,22 @@ func TablePrintStats(title string, freq bool, surveySummary SurveySummary, netwo
 	sortKeysByColumn(keys, primarySortColumn, surveySummary)
 
 	switch networkType {
-	case "2G", "3G":
-		tableWriter.AppendHeader(table.Row{"GSMA", "BAND", "MNO", "CellID", "#", "DBM", "RSSI", "MIN", "MAX", "STD"})
-		appendRowsToTable(tableWriter, keys, surveySummary)
+	case "2G":
+		tableWriter.AppendHeader(table.Row{"GSMA", "BAND", "MNO", "CellID", "#", "CENS", "DBM", "±", "RSSI", "±", "MIN", "MAX", "STD"})
+		appendMetricRows(tableWriter, keys, surveySummary, "RSSI")
+	case "3G":
+		tableWriter.AppendHeader(table.Row{"GSMA", "BAND", "MNO", "CellID", "#", "CENS", "DBM", "±", "RSCP", "±", "MIN", "MAX", "STD", "ECIO", "±", "MIN", "MAX", "STD"})
+		appendMetricRows(tableWriter, keys, surveySummary, "RSCP", "ECIO")
 	case "4G":
-		tableWriter.AppendHeader(table.Row{"GSMA", "BAND", "MNO", "CellID", "#", "DBM", "RSRP", "MIN", "MAX", "STD", "RSRQ", "MIN", "MAX", "STD"})
-		appendRowsToTable4G(tableWriter, keys, surveySummary)
+		tableWriter.AppendHeader(table.Row{"GSMA", "BAND", "MNO", "CellID", "#", "CENS", "DBM", "±", "RSRP", "±", "MIN", "MAX", "STD", "RSRQ", "±", "MIN", "MAX", "STD"})
+		appendMetricRows(tableWriter, keys, surveySummary, "RSRP", "RSRQ")
+	case "5G":
+		tableWriter.AppendHeader(table.Row{"GSMA", "BAND", "MNO", "CellID", "#", "CENS", "DBM", "±", "SS-RSRP", "±", "MIN", "MAX", "STD", "SS-RSRQ", "±", "MIN", "MAX", "STD", "SS-SINR", "±", "MIN", "MAX", "STD"})
+		appendMetricRows(tableWriter, keys, surveySummary, "RSRP", "RSRQ", "SINR")
 	default:
 		return fmt.Errorf("unsupported networkType: %s", networkType)
 	}
+	tableWriter.SetCaption("%s", ciCaption(surveySummary.Options))
 
 	tableWriter.Render()
 	return nil
@@ -289,20 +408,18 @@ func sortKeysByColumn(keys []SurveyKey, primarySortColumn string, surveySummary
 	})
 }
 
-func appendRowsToTable(tableWriter table.Writer, keys []SurveyKey, surveySummary SurveySummary) error {
+// appendMetricRows appends a row per cell: the DBM mean, then the mean,
+// confidence interval, min, max and standard deviation of each metric.
+func appendMetricRows(tableWriter table.Writer, keys []SurveyKey, surveySummary SurveySummary, metrics ...string) error {
 	for _, key := range keys {
 		stat, ok := surveySummary.Stat[key]
 		if !ok {
 			return fmt.Errorf("missing data for key: %v", key)
 		}
 
-		dbm := roundTo2DP(stat["DBM"].Mean)
-		Value := roundTo2DP(stat["RSSI"].Mean)
 		count := stat["DBM"].Number
-		Min := roundTo2DP(stat["RSSI"].Min)
-		Max := roundTo2DP(stat["RSSI"].Max)
-		STD := roundTo2DP(stat["RSSI"].StandardDeviation)
-		color := getColorCoding(int(dbm), int(surveySummary.Min), int(surveySummary.Max))
+		dbmValue := roundTo2DP(stat["DBM"].Mean)
+		color := getColorCoding(int(dbmValue), int(surveySummary.Min), int(surveySummary.Max))
 
 		row := table.Row{
 			color.Sprint(key.NetworkType),
@@ -310,55 +427,141 @@ func appendRowsToTable(tableWriter table.Writer, keys []SurveyKey, surveySummary
 			color.Sprint(key.NetName),
 			color.Sprint(key.CellID),
 			color.Sprint(count),
-			color.Sprint(dbm),
-			color.Sprint(Value),
-			color.Sprint(Max),
-			color.Sprint(Min),
-			color.Sprint(STD),
+			color.Sprint(stat["DBM"].Censored),
+			color.Sprint(dbmValue),
+			color.Sprint(ciCell(stat["DBM"], surveySummary.Options.MaxCI)),
+		}
+		for _, metric := range metrics {
+			row = append(row,
+				color.Sprint(roundTo2DP(stat[metric].Mean)),
+				color.Sprint(ciCell(stat[metric], surveySummary.Options.MaxCI)),
+				color.Sprint(roundTo2DP(stat[metric].Min)),
+				color.Sprint(roundTo2DP(stat[metric].Max)),
+				color.Sprint(roundTo2DP(stat[metric].StandardDeviation)),
+			)
 		}
 		tableWriter.AppendRow(row)
 	}
 	return nil
 }
 
-func appendRowsToTable4G(tableWriter table.Writer, keys []SurveyKey, surveySummary SurveySummary) error {
-	for _, key := range keys {
-		stat, ok := surveySummary.Stat[key]
-		if !ok {
-			return fmt.Errorf("missing data for key: %v", key)
+// ciCell formats the half-width of the confidence interval of a mean, starred
+// when wider than maxCI, and "-" when there is no sample.
+func ciCell(s Stats, maxCI float64) string {
+	if s.Number == 0 {
+		return "-"
+	}
+	halfWidth := s.CIHalfWidth()
+	cell := "∞"
+	if !math.IsInf(halfWidth, 0) && !math.IsNaN(halfWidth) {
+		cell = fmt.Sprint(roundTo2DP(halfWidth))
+	}
+	if maxCI > 0 && !(halfWidth <= maxCI) {
+		cell += " *"
+	}
+	return cell
+}
+
+// ciCaption explains the ± columns.
+func ciCaption(opts StatOptions) string {
+	method := "t"
+	if opts.Bootstrap > 0 {
+		method = fmt.Sprintf("bootstrap, %d resamples", opts.Bootstrap)
+	}
+	caption := fmt.Sprintf("± %g %% confidence interval of the mean (%s)", 100*opts.ConfidenceLevel(), method)
+	if opts.MaxCI > 0 {
+		caption += fmt.Sprintf(", * wider than ±%g dB", opts.MaxCI)
+	}
+	return caption
+}
+
+// deltaCaption explains the test columns of the delta tables.
+func deltaCaption(opts DeltaOptions) string {
+	alpha := opts.AlphaLevel()
+	return fmt.Sprintf("DELTA: difference of the sample %ss; P: %s, DIFFERENT below alpha %g; CI: %g %% bootstrap interval of the delta; D: Cohen's d", opts.Test.Estimator(), opts.Test, alpha, 100*(1-alpha))
+}
+
+// TablePrintCalibration prints the offsets derived by DeriveCalibration for a
+// device.
+func TablePrintCalibration(title string, results []CalibrationResult) {
+	tableWriter := table.NewWriter()
+	tableWriter.SetTitle(title)
+	tableWriter.SetOutputMirror(os.Stdout)
+	tableWriter.AppendHeader(table.Row{"BAND", "OFFSET", "SD", "CELLS"})
+	for _, result := range results {
+		band := fmt.Sprint(result.Band)
+		if result.Band == 0 {
+			band = "ALL"
 		}
+		tableWriter.AppendRow(table.Row{band, roundTo2DP(result.Offset), roundTo2DP(result.Std), result.Cells})
+	}
+	tableWriter.Render()
+}
 
-		count := stat["DBM"].Number
-		// RSRP values
-		ValueRSRP := roundTo2DP(stat["RSRP"].Mean)
-		MinRSRP := roundTo2DP(stat["RSRP"].Min)
-		MaxRSRP := roundTo2DP(stat["RSRP"].Max)
-		STDRSRP := roundTo2DP(stat["RSRP"].StandardDeviation)
-		// RSRQ values
-		ValueRSRQ := roundTo2DP(stat["RSRQ"].Mean)
-		MinRSRQ := roundTo2DP(stat["RSRQ"].Min)
-		MaxRSRQ := roundTo2DP(stat["RSRQ"].Max)
-		STDRSRQ := roundTo2DP(stat["RSRQ"].StandardDeviation)
-		dbmValue := roundTo2DP(stat["DBM"].Mean)
-		color := getColorCoding(int(dbmValue), int(surveySummary.Min), int(surveySummary.Max))
+// TablePrintValidation prints the score of a survey and its findings.
+func TablePrintValidation(report ValidationReport) {
+	status := "PASS"
+	if !report.Passed {
+		status = "FAIL"
+	}
+	tableWriter := table.NewWriter()
+	tableWriter.SetTitle(fmt.Sprintf("%s score %d/100 %s", report.Name, report.Score, status))
+	tableWriter.SetOutputMirror(os.Stdout)
+	tableWriter.AppendHeader(table.Row{"SEVERITY", "CHECK", "FINDING", "COUNT", "ROUNDS"})
+	for _, finding := range report.Findings {
+		count := "-"
+		if finding.Count > 0 {
+			count = fmt.Sprint(finding.Count)
+		}
+		rounds := "-"
+		if len(finding.Rounds) > 0 {
+			rounds = strings.Trim(fmt.Sprint(finding.Rounds), "[]")
+			if finding.Count > len(finding.Rounds) {
+				rounds += " …"
+			}
+		}
+		severity := finding.Severity.String()
+		switch finding.Severity {
+		case SeverityError:
+			severity = text.Colors{text.FgRed}.Sprint(severity)
+		case SeverityWarning:
+			severity = text.Colors{text.FgYellow}.Sprint(severity)
+		}
+		tableWriter.AppendRow(table.Row{severity, finding.Check, finding.Message, count, rounds})
+	}
+	tableWriter.Render()
+}
 
-		row := table.Row{
-			color.Sprint(key.NetworkType),
-			color.Sprint(key.Band),
-			color.Sprint(key.NetName),
-			color.Sprint(key.CellID),
-			color.Sprint(count),
-			color.Sprint(dbmValue),
-			color.Sprint(ValueRSRP),
-			color.Sprint(MaxRSRP),
-			color.Sprint(MinRSRP),
-			color.Sprint(STDRSRP),
-			color.Sprint(ValueRSRQ),
-			color.Sprint(MaxRSRQ),
-			color.Sprint(MinRSRQ),
-			color.Sprint(STDRSRQ),
+// TablePrintPercentiles prints the percentiles of each distribution. With a
+// threshold, the COVERAGE column gives the share of samples at or above it.
+func TablePrintPercentiles(title string, distributions []ECDF, threshold *float64) {
+	tableWriter := table.NewWriter()
+	tableWriter.SetTitle(title)
+	tableWriter.SetOutputMirror(os.Stdout)
+	header := table.Row{"GSMA", "MNO", "BAND", "CELLID", "#", "CENS"}
+	for _, rank := range PercentileRanks {
+		header = append(header, percentileLabel(rank))
+	}
+	if threshold != nil {
+		header = append(header, fmt.Sprintf("COVERAGE ≥ %g", *threshold))
+	}
+	tableWriter.AppendHeader(header)
+	for _, e := range distributions {
+		band, cell := fmt.Sprint(e.Key.Band), fmt.Sprint(e.Key.CellID)
+		if e.Key.Band == 0 {
+			band = "ALL"
+		}
+		if e.Key.CellID == 0 {
+			cell = "ALL"
+		}
+		row := table.Row{e.Key.NetworkType, e.Key.NetName, band, cell, e.Number, e.Censored}
+		for _, percentile := range e.Percentiles {
+			row = append(row, roundTo2DP(percentile))
+		}
+		if threshold != nil {
+			row = append(row, fmt.Sprintf("%.1f %%", 100*e.Above(*threshold)))
 		}
 		tableWriter.AppendRow(row)
 	}
-	return nil
+	tableWriter.Render()
 }
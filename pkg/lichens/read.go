@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -14,23 +15,98 @@ import (
 
 const headerRows = 14
 
-func ReadMultiCSV(filename string) (SurveyInfo, error) {
-	var survey SurveyInfo
+// StdinName is the filename that makes ReadMultiCSV read from standard input.
+const StdinName = "-"
+
+// ParseOptions controls how a survey stream is parsed.
+type ParseOptions struct {
+	// CheckFilename enforces the Siretta Lnnnnnnn.csv naming on the base name
+	// of the file. It is ignored for streams and standard input.
+	CheckFilename bool
+	// Mode selects strict or lenient handling of malformed rows.
+	Mode ParseMode
+	// Name identifies the source in ParseError diagnostics.
+	Name string
+	// DateOrder forces day-first or month-first timestamps; DateAuto detects
+	// the order from the whole file.
+	DateOrder DateOrder
+	// Location is the timezone of the survey timestamps, UTC when nil.
+	Location *time.Location
+	// Calibration holds the device profiles applied to the levels read.
+	Calibration CalibrationProfiles
+}
+
+// ReadMultiCSV opens a Siretta survey file, or standard input when filename
+// is StdinName, and parses it with ParseSurvey. Gzip compressed files are
+// decompressed, and a zip archive is read when it holds a single survey.
+func ReadMultiCSV(filename string, opts ParseOptions) (SurveyInfo, error) {
+	if isZipFilename(filename) {
+		surveys, err := ReadSurveyFile(filename, opts)
+		if err != nil {
+			return SurveyInfo{}, err
+		}
+		if len(surveys) != 1 {
+			return SurveyInfo{}, fmt.Errorf("archive %s holds %d surveys, use ReadSurveyDir", filename, len(surveys))
+		}
+		return surveys[SortedFilenames(surveys)[0]], nil
+	}
+	return readSurveyPath(filename, opts, ParseSurvey)
+}
 
-	if !isValidSirettaLFilename(filename) {
-		return survey, fmt.Errorf("invalid filename pattern")
+// readSurveyPath opens filename, or standard input for StdinName, and hands
+// the decompressed stream to parse.
+func readSurveyPath(filename string, opts ParseOptions, parse func(io.Reader, ParseOptions) (SurveyInfo, error)) (SurveyInfo, error) {
+	if opts.Name == "" {
+		opts.Name = filename
 	}
-	survey.Surveys = make(map[SurveyKey]SurveyDataSlice)
 
-	input, err := os.Open(filename)
+	var input io.ReadCloser
+	if filename == StdinName {
+		opts.Name = "stdin"
+		input = os.Stdin
+	} else {
+		if opts.CheckFilename && !isValidSirettaLFilename(trimGzipExt(filepath.Base(filename))) {
+			return SurveyInfo{}, fmt.Errorf("invalid filename pattern: %s", filename)
+		}
+
+		var err error
+		if input, err = os.Open(filename); err != nil {
+			return SurveyInfo{}, err
+		}
+		defer input.Close()
+	}
+
+	r, err := decompress(input)
 	if err != nil {
-		return survey, err
+		return SurveyInfo{}, err
+	}
+	return parse(r, opts)
+}
+
+// ParseSurvey reads a Siretta GRAPHYTE survey from r and applies the
+// calibration profile of its device.
+func ParseSurvey(r io.Reader, opts ParseOptions) (SurveyInfo, error) {
+	survey, err := parseSurvey(r, opts)
+	if err == nil {
+		applyCalibration(&survey, opts.Calibration)
+	}
+	return survey, err
+}
+
+func parseSurvey(r io.Reader, opts ParseOptions) (SurveyInfo, error) {
+	var survey SurveyInfo
+	survey.Surveys = make(map[SurveyKey]SurveyDataSlice)
+	if opts.Name == "" {
+		opts.Name = "survey"
 	}
-	defer input.Close()
 
-	reader := csv.NewReader(input)
+	reader := csv.NewReader(r)
+	// Header rows and survey rows have different column counts.
+	reader.FieldsPerRecord = -1
 
 	// Parsing header rows
+	var fileCreated string
+	var fileCreatedLine int
 	for i := 0; i < headerRows; i++ {
 		record, err := reader.Read()
 		if err == io.EOF {
@@ -40,18 +116,20 @@ func ReadMultiCSV(filename string) (SurveyInfo, error) {
 			return survey, err
 		}
 
+		if i >= 6 && i <= 13 && len(record) < 2 {
+			line, _ := reader.FieldPos(0)
+			return survey, &ParseError{File: opts.Name, Line: line, Err: errors.New("header row has no value")}
+		}
+
 		// Extract relevant information based on the current header row.
 		switch i {
 		case 6:
 			survey.SurveyType = strings.TrimSpace(record[1])
 			// fmt.Println(record[1])
 		case 7:
-			// fmt.Println(record[1])
-			survey.FileCreated, err = time.Parse("02/01/06 15:04:05", record[1])
-			// fmt.Println(survey.FileCreated)
-			if err != nil {
-				return survey, errors.New("invalid date format in FileCreated")
-			}
+			// Parsed once the date order of the file is known.
+			fileCreated = strings.TrimSpace(record[1])
+			fileCreatedLine, _ = reader.FieldPos(1)
 		case 8:
 			survey.IMEINumber = record[1]
 		case 9:
@@ -62,80 +140,251 @@ func ReadMultiCSV(filename string) (SurveyInfo, error) {
 			survey.FirmwareVersion = record[1]
 		case 12:
 			survey.Filename = record[1]
+		case 13:
+			value := strings.TrimPrefix(strings.TrimSpace(record[1]), "'")
+			if survey.Timestamp, err = strconv.Atoi(value); err != nil {
+				line, _ := reader.FieldPos(1)
+				return survey, &ParseError{File: opts.Name, Line: line, Column: "Timestamp", Value: record[1], Err: err}
+			}
 		}
-		// Allow for variable columns per row.
-		reader.FieldsPerRecord = 0
 	}
-	// fmt.Println(survey)
-	// Parsing survey data
+	// Reading survey data. Each scan round repeats the column header, which is
+	// used to map the row values to SurveyData fields. Rows are kept until the
+	// whole file is read so the date order can be detected from all of them.
+	var cols *columnMap
+	var rows []rowParser
+	timestamps := []string{fileCreated}
 	for {
 		record, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
+			// FieldPos is only valid for a record that was read: a malformed
+			// row reports its line in the csv.ParseError.
+			var csvErr *csv.ParseError
+			if errors.As(err, &csvErr) {
+				parseErr := ParseError{File: opts.Name, Line: csvErr.Line, Err: csvErr.Err}
+				if opts.Mode == ParseLenient {
+					survey.Warnings = append(survey.Warnings, ParseWarning{parseErr})
+					continue
+				}
+				return survey, &parseErr
+			}
 			return survey, err
 		}
+		line, _ := reader.FieldPos(0)
+
+		if isColumnHeader(record) {
+			if cols, err = newColumnMap(record); err != nil {
+				return survey, &ParseError{File: opts.Name, Line: line, Err: err}
+			}
+			continue
+		}
+		if cols == nil {
+			return survey, &ParseError{File: opts.Name, Line: line, Err: errors.New("survey data found before the column header")}
+		}
 
-		// Handle special case for "Survey:" row or parse survey data.
-		switch record[0] {
-		case "Survey:":
-			// Handle the "Survey:" case if needed. If there's no specific action, this can be omitted.
-		default:
-			var key SurveyKey
-			var surveyData SurveyData
-			if surveyData, key, err = parseSurveyData(record); err != nil {
-				return survey, err
+		rows = append(rows, rowParser{record: record, cols: cols, file: opts.Name, line: line})
+		timestamps = append(timestamps, cols.get(record, ColTimestamp))
+	}
+
+	order := opts.DateOrder
+	if order == DateAuto {
+		order = DetectDateOrder(timestamps)
+	}
+	location := opts.Location
+	if location == nil {
+		location = time.UTC
+	}
+	survey.DateOrder = order
+	survey.Location = location
+
+	var err error
+	survey.FileCreated, err = time.ParseInLocation(order.Layout(), fileCreated, location)
+	if err != nil {
+		return survey, &ParseError{File: opts.Name, Line: fileCreatedLine, Column: "File Created", Value: fileCreated, Err: err}
+	}
+
+	// Parsing survey data
+	for _, row := range rows {
+		row.layout = order.Layout()
+		row.location = location
+		surveyData, key := row.surveyData()
+		if row.err != nil {
+			if opts.Mode == ParseLenient {
+				survey.Warnings = append(survey.Warnings, ParseWarning{*row.err})
+				continue
 			}
-			survey.Surveys[key] = append(survey.Surveys[key], surveyData)
+			return survey, row.err
 		}
+		survey.Surveys[key] = append(survey.Surveys[key], surveyData)
 	}
+	survey.Rounds = BuildRounds(survey.Surveys)
 
 	return survey, nil
 }
 
-// parseSurveyData extracts survey data from a record row and returns the parsed data.
-func parseSurveyData(record []string) (SurveyData, SurveyKey, error) {
+// notReported is the placeholder Siretta writes for values a RAT does not have.
+const notReported = "-"
+
+// rowParser converts the columns of one survey row and keeps the first
+// conversion error.
+type rowParser struct {
+	record []string
+	cols   *columnMap
+	file   string
+	line   int
+	err    *ParseError
+
+	layout   string
+	location *time.Location
+}
+
+func (p *rowParser) fail(column, value string, err error) {
+	if p.err == nil {
+		p.err = &ParseError{File: p.file, Line: p.line, Column: column, Value: value, Err: err}
+	}
+}
+
+func (p *rowParser) string(column string) string {
+	return p.cols.get(p.record, column)
+}
+
+func (p *rowParser) int(column string) int {
+	value := p.cols.get(p.record, column)
+	if value == "" || value == notReported {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.fail(column, value, err)
+	}
+	return n
+}
+
+func (p *rowParser) time(column string) time.Time {
+	value := p.cols.get(p.record, column)
+	t, err := time.ParseInLocation(p.layout, value, p.location)
+	if err != nil {
+		p.fail(column, value, err)
+	}
+	return t
+}
+
+func (p *rowParser) optionalFloat(column string) OptionalFloat {
+	value := p.cols.get(p.record, column)
+	if value == "" || value == notReported {
+		return OptionalFloat{}
+	}
+	return ReportedFloat(p.float(column))
+}
+
+// bandNumber reads a band number, accepting the "n78" notation of NR bands.
+func (p *rowParser) bandNumber(column string) int {
+	value := p.cols.get(p.record, column)
+	if isNRBand(value) {
+		n, err := strconv.Atoi(value[1:])
+		if err != nil {
+			p.fail(column, value, err)
+		}
+		return n
+	}
+	return p.int(column)
+}
+
+func (p *rowParser) optionalInt(column string) OptionalInt {
+	value := p.cols.get(p.record, column)
+	if value == "" || value == notReported {
+		return OptionalInt{}
+	}
+	return OptionalInt{Value: p.int(column), Valid: true}
+}
+
+func (p *rowParser) bsic(column string) BSIC {
+	bsic := p.optionalInt(column)
+	if bsic.Valid && (bsic.Value < 0 || bsic.Value > 63) {
+		p.fail(column, p.cols.get(p.record, column), errors.New("BSIC out of range 0-63"))
+	}
+	return BSIC(bsic)
+}
+
+func (p *rowParser) float(column string) float64 {
+	value := p.cols.get(p.record, column)
+	if value == "" || value == notReported {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		p.fail(column, value, err)
+	}
+	return f
+}
+
+// surveyData extracts survey data from a record row and returns the parsed data.
+func (p *rowParser) surveyData() (SurveyData, SurveyKey) {
 	var surveyData SurveyData
 	var key SurveyKey
 
-	// Extract and convert data from the record.
-	// This is just a simple example, in a real-world scenario,
-	// error handling and parsing would be much more exhaustive.
-	// You can also consider breaking this into smaller functions.
-	surveyData.Survey, _ = strconv.Atoi(record[0])
-
-	surveyData.Timestamp, _ = time.Parse("01/02/06 15:04:05", record[1])
-	// surveyData.Network = record[2]
-	surveyData.Index, _ = strconv.Atoi(record[3])
-	surveyData.XRFCN, _ = strconv.Atoi(record[4])
-	surveyData.DBM, _ = strconv.ParseFloat(record[5], 64)
-	surveyData.Percentage, _ = strconv.ParseFloat(record[6], 64)
-	surveyData.RSSI, _ = strconv.ParseFloat(record[7], 64)
-	surveyData.MCC, _ = strconv.Atoi(record[8])
-	surveyData.MNC, _ = strconv.Atoi(record[9])
-
-	surveyData.LACTAC, _ = strconv.Atoi(record[11])
-	surveyData.BandNum, _ = strconv.Atoi(record[12])
-
-	surveyData.BSIC = record[14]
-	surveyData.SCR = record[15]
-	surveyData.ECIO = record[16]
-	surveyData.RSCP, _ = strconv.ParseFloat(record[17], 64)
-	surveyData.PCI, _ = strconv.Atoi(record[18])
-	surveyData.RSRP, _ = strconv.ParseFloat(record[19], 64)
-	surveyData.RSRQ, _ = strconv.ParseFloat(record[20], 64)
-	surveyData.BW, _ = strconv.Atoi(record[21])
-	surveyData.DL, _ = strconv.ParseFloat(record[22], 64)
-	surveyData.UL, _ = strconv.ParseFloat(record[23], 64)
-
-	key.NetworkType = record[2]
-
-	key.CellID, _ = strconv.Atoi(record[10])
-	frequencyParts := strings.Split(record[13], " ")
-	key.Band, _ = strconv.Atoi(frequencyParts[0])
-	key.NetName = record[24]
-	return surveyData, key, nil
+	surveyData.Survey = p.int(ColSurvey)
+	surveyData.Timestamp = p.time(ColTimestamp)
+	surveyData.Network = p.string(ColNetwork)
+	if networkType, err := ParseGSMAType(surveyData.Network); err == nil {
+		surveyData.Network = networkType.String()
+	}
+	surveyData.Index = p.int(ColIndex)
+	surveyData.XRFCN = p.int(ColXRFCN)
+	surveyData.DBM = p.float(ColDBM)
+	surveyData.Percentage = p.optionalFloat(ColPercentage)
+	surveyData.RSSI = p.optionalFloat(ColRSSI)
+	surveyData.MCC = p.int(ColMCC)
+	surveyData.MNC = p.int(ColMNC)
+	surveyData.CellID = p.int(ColCellID)
+	surveyData.LACTAC = p.int(ColLACTAC)
+	surveyData.BandNum = p.bandNumber(ColBandNum)
+
+	surveyData.BSIC = p.bsic(ColBSIC)
+	surveyData.SCR = p.optionalInt(ColSCR)
+	surveyData.ECIO = p.optionalFloat(ColECIO)
+	surveyData.RSCP = p.optionalFloat(ColRSCP)
+	surveyData.PCI = p.int(ColPCI)
+	surveyData.RSRP = p.optionalFloat(ColRSRP)
+	surveyData.RSRQ = p.optionalFloat(ColRSRQ)
+	surveyData.BW = p.int(ColBW)
+	surveyData.DL = p.float(ColDL)
+	surveyData.UL = p.float(ColUL)
+	surveyData.SINR = p.optionalFloat(ColSINR)
+	surveyData.SCS = p.int(ColSCS)
+	surveyData.NetName = p.string(ColNetName)
+	surveyData.Signal = p.string(ColSignal)
+	surveyData.Extra = p.cols.extra(p.record)
+
+	// The Band column reads "2600 MHz"; the key keeps the frequency. 5G rows
+	// may only give the NR band ("n78"), which is mapped to its frequency.
+	band := p.string(ColBand)
+	if frequencyParts := strings.Fields(band); len(frequencyParts) > 0 {
+		if isNRBand(frequencyParts[0]) {
+			number, _ := strconv.Atoi(frequencyParts[0][1:])
+			if surveyData.BandNum == 0 {
+				surveyData.BandNum = number
+			}
+			frequencyParts = frequencyParts[1:]
+		}
+		if len(frequencyParts) > 0 {
+			var err error
+			if surveyData.Band, err = strconv.Atoi(frequencyParts[0]); err != nil {
+				p.fail(ColBand, band, err)
+			}
+		} else if surveyData.Band = nrBandFrequency[surveyData.BandNum]; surveyData.Band == 0 {
+			surveyData.Band = int(NRARFCNFrequency(surveyData.XRFCN))
+		}
+	}
+
+	key.NetworkType = surveyData.Network
+	key.CellID = surveyData.CellID
+	key.Band = surveyData.Band
+	key.NetName = surveyData.NetName
+	return surveyData, key
 }
 
 func isValidSirettaLFilename(filename string) bool {
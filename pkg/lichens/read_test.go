@@ -0,0 +1,187 @@
+package lichens
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testSurvey returns a GRAPHYTE survey with the header block of a 4G survey
+// followed by lines, joined with the CRLF line endings of the device.
+func testSurvey(lines ...string) string {
+	header := []string{
+		"=================",
+		"Siretta Limited",
+		"=================",
+		"GRAPHYTE Network Survey Results",
+		"www.siretta.com",
+		"+44 1189 769 000",
+		"Survey Type, 4G",
+		"File Created,24/03/23 09:20:03",
+		"IMEI Number,'351626102376784",
+		"Hardware Version,'GRAPHYTE LTE V2 (EU)",
+		"Application Version,'6.10.19",
+		"Firmware Version,'M0F.670010",
+		"Filename,'L3240918.csv",
+		"Timestamp,'0",
+	}
+	return strings.Join(append(header, lines...), "\r\n") + "\r\n"
+}
+
+const (
+	testColumns = "Survey:,Timestamp:,Network:,Index:,xRFCN:,dBm:,%:,RSSI:,MCC:,MNC:,CellID:,LAC/TAC:,Band Num:,Band:,BSIC:,SCR:,ECIO:,RSCP:,PCI:,RSRP:,RSRQ:,BW:,DL:,UL:,Net Name:,Signal:"
+	testRow     = "1,24/03/23 09:20:03,4G,1,3000,-33,89,68,208,01,19772943,24674,7,2600 MHz,-,-,-,-,391,-73,-20.0,20,2645.0,2525.0,Orange,|||"
+)
+
+func TestParseSurveyColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns string
+		row     string
+		extra   map[string]string
+	}{
+		{"graphyte", testColumns, testRow, nil},
+		{
+			"reordered",
+			"Net Name:,Band:,CellID:,dBm:,RSRP:,Network:,Timestamp:,Survey:",
+			"Orange,2600 MHz,19772943,-33,-73,4G,24/03/23 09:20:03,1",
+			nil,
+		},
+		{
+			"unknown column",
+			"Survey:,Timestamp:,Network:,CellID:,Band:,dBm:,RSRP:,Net Name:,Altitude:",
+			"1,24/03/23 09:20:03,4G,19772943,2600 MHz,-33,-73,Orange,112",
+			map[string]string{"Altitude": "112"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			survey, err := ParseSurvey(strings.NewReader(testSurvey(test.columns, test.row)), ParseOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			key := SurveyKey{Band: 2600, CellID: 19772943, NetName: "Orange", NetworkType: "4G"}
+			slice := survey.Surveys[key]
+			if len(survey.Surveys) != 1 || len(slice) != 1 {
+				t.Fatalf("Surveys = %v, want one sample of %v", survey.Surveys, key)
+			}
+			data := slice[0]
+			if data.DBM != -33 || data.RSRP != ReportedFloat(-73) {
+				t.Errorf("DBM, RSRP = %v, %v, want -33, -73", data.DBM, data.RSRP)
+			}
+			if len(data.Extra) != len(test.extra) {
+				t.Errorf("Extra = %v, want %v", data.Extra, test.extra)
+			}
+			for name, value := range test.extra {
+				if data.Extra[name] != value {
+					t.Errorf("Extra[%q] = %q, want %q", name, data.Extra[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSurveyMissingColumn(t *testing.T) {
+	columns := "Survey:,Timestamp:,Network:,CellID:,Band:,RSRP:,Net Name:"
+	row := "1,24/03/23 09:20:03,4G,19772943,2600 MHz,-73,Orange"
+	if _, err := ParseSurvey(strings.NewReader(testSurvey(columns, row)), ParseOptions{}); err == nil {
+		t.Error("no error for a header without dBm")
+	}
+}
+
+func TestParseSurveyModes(t *testing.T) {
+	badIndex := strings.Replace(testRow, ",4G,1,", ",4G,x,", 1)
+	badQuote := strings.Replace(testRow, "Orange", `Or"ange`, 1)
+	for _, test := range []struct {
+		name   string
+		row    string
+		column string
+	}{
+		{"invalid value", badIndex, ColIndex},
+		{"malformed csv", badQuote, ""},
+	} {
+		// Line 15 is the column header, 16 a good row and 17 the bad one.
+		input := testSurvey(testColumns, testRow, test.row, testRow)
+
+		t.Run(test.name+" strict", func(t *testing.T) {
+			_, err := ParseSurvey(strings.NewReader(input), ParseOptions{Name: "L3240918.csv"})
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("error %v is not a ParseError", err)
+			}
+			if parseErr.File != "L3240918.csv" || parseErr.Line != 17 || parseErr.Column != test.column {
+				t.Errorf("ParseError at %s:%d column %q, want L3240918.csv:17 column %q", parseErr.File, parseErr.Line, parseErr.Column, test.column)
+			}
+		})
+
+		t.Run(test.name+" lenient", func(t *testing.T) {
+			survey, err := ParseSurvey(strings.NewReader(input), ParseOptions{Mode: ParseLenient})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(survey.Warnings) != 1 || survey.Warnings[0].Line != 17 || survey.Warnings[0].Column != test.column {
+				t.Errorf("Warnings = %v, want one at line 17", survey.Warnings)
+			}
+			samples := 0
+			for _, slice := range survey.Surveys {
+				samples += len(slice)
+			}
+			if samples != 2 {
+				t.Errorf("%d samples kept, want 2", samples)
+			}
+		})
+	}
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	for _, test := range []struct {
+		err  ParseError
+		want string
+	}{
+		{ParseError{File: "L3240918.csv", Line: 15, Err: errors.New("survey data found before the column header")}, "L3240918.csv:15: survey data found before the column header"},
+		{ParseError{File: "L3240918.csv", Line: 17, Column: ColIndex, Value: "x", Err: errors.New("invalid syntax")}, `L3240918.csv:17: column Index: invalid value "x": invalid syntax`},
+	} {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("Error() = %q, want %q", got, test.want)
+		}
+		if got := (ParseWarning{test.err}).String(); got != test.want {
+			t.Errorf("String() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestParseSurveyOptionalValues(t *testing.T) {
+	columns := "Survey:,Timestamp:,Network:,CellID:,Band:,dBm:,Net Name:,RSRP:,RSRQ:,RSCP:,SCR:,BSIC:"
+	for _, test := range []struct {
+		name       string
+		values     string
+		rsrp, rscp OptionalFloat
+		scr        OptionalInt
+		bsic       BSIC
+	}{
+		{"not reported", "-,-,-,-,-", OptionalFloat{}, OptionalFloat{}, OptionalInt{}, BSIC{}},
+		{"empty", ",,,,", OptionalFloat{}, OptionalFloat{}, OptionalInt{}, BSIC{}},
+		{"zero", "0,0,0,0,0", ReportedFloat(0), ReportedFloat(0), OptionalInt{Valid: true}, BSIC{Valid: true}},
+		{"reported", "-73,-20.0,-81,212,63", ReportedFloat(-73), ReportedFloat(-81), OptionalInt{Value: 212, Valid: true}, BSIC{Value: 63, Valid: true}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			row := "1,24/03/23 09:20:03,4G,19772943,2600 MHz,-33,Orange," + test.values
+			survey, err := ParseSurvey(strings.NewReader(testSurvey(columns, row)), ParseOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(survey.Surveys) != 1 {
+				t.Fatalf("Surveys = %v, want one cell", survey.Surveys)
+			}
+			for _, slice := range survey.Surveys {
+				data := slice[0]
+				if data.RSRP != test.rsrp || data.RSCP != test.rscp {
+					t.Errorf("RSRP, RSCP = %v, %v, want %v, %v", data.RSRP, data.RSCP, test.rsrp, test.rscp)
+				}
+				if data.SCR != test.scr || data.BSIC != test.bsic {
+					t.Errorf("SCR, BSIC = %v, %v, want %v, %v", data.SCR, data.BSIC, test.scr, test.bsic)
+				}
+			}
+		})
+	}
+}
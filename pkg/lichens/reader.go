@@ -0,0 +1,107 @@
+package lichens
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// sniffLen is how much of a file the readers get to recognise their format.
+const sniffLen = 4096
+
+// SurveyReader reads the surveys of one file format into SurveyInfo, so that
+// any source feeds the same statistics, attenuation and gain pipeline.
+type SurveyReader interface {
+	// Sniff reports whether the start of a file is in the reader's format.
+	Sniff(head []byte) bool
+	// Read parses a whole survey.
+	Read(r io.Reader) (SurveyInfo, error)
+}
+
+// ConfigurableReader is implemented by readers that honour ParseOptions.
+type ConfigurableReader interface {
+	SurveyReader
+	// WithOptions returns a reader using opts.
+	WithOptions(opts ParseOptions) SurveyReader
+}
+
+var (
+	readersMu sync.RWMutex
+	readers   []SurveyReader
+)
+
+func init() {
+	RegisterReader(SirettaReader{})
+	RegisterReader(GNetTrackReader{})
+	RegisterReader(ATCaptureReader{})
+}
+
+// RegisterReader adds a reader to the registry. Readers are sniffed in
+// registration order. The built-in readers are registered first, in the order
+// set by the init of this file, so a reader added by another package is only
+// tried on the files none of them recognises.
+func RegisterReader(reader SurveyReader) {
+	readersMu.Lock()
+	defer readersMu.Unlock()
+	readers = append(readers, reader)
+}
+
+// Readers returns the registered readers.
+func Readers() []SurveyReader {
+	readersMu.RLock()
+	defer readersMu.RUnlock()
+	return append([]SurveyReader(nil), readers...)
+}
+
+// SniffReader returns the first registered reader recognising head.
+func SniffReader(head []byte) (SurveyReader, error) {
+	for _, reader := range Readers() {
+		if reader.Sniff(head) {
+			return reader, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown survey format")
+}
+
+// ReadSurvey detects the format of r with the registered readers and reads it,
+// applying the calibration profile of the device.
+func ReadSurvey(r io.Reader, opts ParseOptions) (SurveyInfo, error) {
+	buffered := bufio.NewReaderSize(r, sniffLen)
+	head, err := buffered.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return SurveyInfo{}, err
+	}
+
+	reader, err := SniffReader(head)
+	if err != nil {
+		return SurveyInfo{}, err
+	}
+	if configurable, ok := reader.(ConfigurableReader); ok {
+		reader = configurable.WithOptions(opts)
+	}
+	survey, err := reader.Read(buffered)
+	if err == nil {
+		applyCalibration(&survey, opts.Calibration)
+	}
+	return survey, err
+}
+
+// SirettaReader reads Siretta GRAPHYTE survey CSV files with ParseSurvey.
+type SirettaReader struct {
+	Options ParseOptions
+}
+
+// Sniff recognises the GRAPHYTE banner or the survey column header.
+func (s SirettaReader) Sniff(head []byte) bool {
+	return bytes.Contains(head, []byte("GRAPHYTE")) || bytes.Contains(head, []byte("Survey:,Timestamp:"))
+}
+
+func (s SirettaReader) Read(r io.Reader) (SurveyInfo, error) {
+	return parseSurvey(r, s.Options)
+}
+
+func (s SirettaReader) WithOptions(opts ParseOptions) SurveyReader {
+	return SirettaReader{Options: opts}
+}
@@ -0,0 +1,46 @@
+package lichens
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSniffReader(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		head string
+		want SurveyReader
+	}{
+		{"graphyte", testSurvey(testColumns, testRow), SirettaReader{}},
+		{"graphyte rows only", testColumns + "\r\n" + testRow + "\r\n", SirettaReader{}},
+		{"g-nettrack", "Timestamp\tLongitude\tLatitude\tOperatorname\tNetworkTech\tLevel\tQual\n2023.03.24_09.20.03\t2.35\t48.85\tOrange F\tLTE\t-80\t-10\n", GNetTrackReader{}},
+		{"quectel", "[2023-03-24 09:20:03] +QENG: \"servingcell\",\"NOCONN\",\"LTE\",\"FDD\",208,01,1A2D003,310,6300,20,5,5,6F1C,-95,-11,-65,12,40\nOK\n", ATCaptureReader{}},
+		{"u-blox", "2023-03-24T09:20:33Z +UCGED: 2\n6,4,208,10\n", ATCaptureReader{}},
+	} {
+		reader, err := SniffReader([]byte(test.head))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if reflect.TypeOf(reader) != reflect.TypeOf(test.want) {
+			t.Errorf("%s: sniffed %T, want %T", test.name, reader, test.want)
+		}
+	}
+
+	if _, err := SniffReader([]byte("date,lat,lon,rsrp\n")); err == nil {
+		t.Error("no error for an unknown format")
+	}
+}
+
+func TestReadersOrder(t *testing.T) {
+	want := []SurveyReader{SirettaReader{}, GNetTrackReader{}, ATCaptureReader{}}
+	readers := Readers()
+	if len(readers) < len(want) {
+		t.Fatalf("%d readers registered, want at least %d", len(readers), len(want))
+	}
+	for i, reader := range want {
+		if reflect.TypeOf(readers[i]) != reflect.TypeOf(reader) {
+			t.Errorf("reader %d is %T, want %T", i, readers[i], reader)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package lichens
+
+import (
+	"sort"
+	"time"
+)
+
+// SurveyRound is one scan of the survey: the cells the modem saw, in scan
+// order, and when the scan started.
+type SurveyRound struct {
+	Number int
+	Start  time.Time
+	Cells  []SurveyCell
+}
+
+// SurveyCell is a cell measured during a round.
+type SurveyCell struct {
+	Key  SurveyKey
+	Data SurveyData
+}
+
+// BuildRounds groups the samples of a survey map by their Survey round number.
+// Rounds are ordered by number and their cells by Index, then by key.
+func BuildRounds(surveys SurveyMap) []SurveyRound {
+	byNumber := make(map[int]*SurveyRound)
+	for key, slice := range surveys {
+		for _, data := range slice {
+			round, ok := byNumber[data.Survey]
+			if !ok {
+				round = &SurveyRound{Number: data.Survey, Start: data.Timestamp}
+				byNumber[data.Survey] = round
+			}
+			if data.Timestamp.Before(round.Start) {
+				round.Start = data.Timestamp
+			}
+			round.Cells = append(round.Cells, SurveyCell{Key: key, Data: data})
+		}
+	}
+
+	rounds := make([]SurveyRound, 0, len(byNumber))
+	for _, round := range byNumber {
+		sort.Slice(round.Cells, func(i, j int) bool {
+			a, b := round.Cells[i], round.Cells[j]
+			if a.Data.Index != b.Data.Index {
+				return a.Data.Index < b.Data.Index
+			}
+			return keyLess(a.Key, b.Key)
+		})
+		rounds = append(rounds, *round)
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i].Number < rounds[j].Number })
+	return rounds
+}
+
+// keyLess orders keys by network type, operator, band and cell.
+func keyLess(a, b SurveyKey) bool {
+	switch {
+	case a.NetworkType != b.NetworkType:
+		return a.NetworkType < b.NetworkType
+	case a.NetName != b.NetName:
+		return a.NetName < b.NetName
+	case a.Band != b.Band:
+		return a.Band < b.Band
+	}
+	return a.CellID < b.CellID
+}
+
+// Round returns the round with the given number.
+func (s SurveyInfo) Round(number int) (SurveyRound, bool) {
+	i := sort.Search(len(s.Rounds), func(i int) bool { return s.Rounds[i].Number >= number })
+	if i < len(s.Rounds) && s.Rounds[i].Number == number {
+		return s.Rounds[i], true
+	}
+	return SurveyRound{}, false
+}
+
+// Cell returns the measurement of a cell during the round.
+func (r SurveyRound) Cell(key SurveyKey) (SurveyData, bool) {
+	for _, cell := range r.Cells {
+		if cell.Key == key {
+			return cell.Data, true
+		}
+	}
+	return SurveyData{}, false
+}
+
+// Surveys returns the round as a survey map, to compute statistics of a
+// single scan with SurveyStatGen.
+func (r SurveyRound) Surveys() SurveyMap {
+	surveys := make(SurveyMap)
+	for _, cell := range r.Cells {
+		surveys[cell.Key] = append(surveys[cell.Key], cell.Data)
+	}
+	return surveys
+}
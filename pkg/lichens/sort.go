@@ -125,6 +125,17 @@ func SelectStats(data SurveyStatsMap, filter SurveyKey) SurveyStatsMap {
 	return newData
 }
 
+// SelectSurveys returns the cells of data that match filter, as KeyFilter.
+func SelectSurveys(data SurveyMap, filter SurveyKey) SurveyMap {
+	newData := make(SurveyMap)
+	for k, v := range data {
+		if KeyFilter(k, filter) {
+			newData[k] = v
+		}
+	}
+	return newData
+}
+
 func SelectDeltaStats(data SurveyDeltaMap, filter SurveyKey) (SurveyDeltaMap, error) {
 	// Check if data is nil
 	if data == nil {
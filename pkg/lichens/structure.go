@@ -1,10 +1,15 @@
 package lichens
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
 const MinimumSampleCount = 2
+
+// MinimumSignalLevel is the sensitivity floor of the DBM readings: lower
+// values are censored at this level by the statistics.
 const MinimumSignalLevel = -129.99
 
 type GSMAType int64
@@ -16,6 +21,37 @@ const (
 	FiveG
 )
 
+func (t GSMAType) String() string {
+	switch t {
+	case GSM:
+		return "2G"
+	case ThreeG:
+		return "3G"
+	case FourG:
+		return "4G"
+	case FiveG:
+		return "5G"
+	default:
+		return fmt.Sprintf("GSMAType(%d)", int64(t))
+	}
+}
+
+// ParseGSMAType accepts a generation ("4G") or a technology name ("LTE", "NR").
+func ParseGSMAType(s string) (GSMAType, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "2G", "GSM", "GPRS", "EDGE":
+		return GSM, nil
+	case "3G", "UMTS", "WCDMA", "HSPA":
+		return ThreeG, nil
+	case "4G", "LTE", "LTE-M", "CAT-M1", "NB-IOT":
+		return FourG, nil
+	case "5G", "NR", "5G NR", "NR5G":
+		return FiveG, nil
+	default:
+		return GSM, fmt.Errorf("unknown network type %q", s)
+	}
+}
+
 type DeltaType string
 
 const (
@@ -29,6 +65,7 @@ type SurveyDeltaStatsSummary struct {
 	DeltaType  DeltaType
 	Min        float64
 	Max        float64
+	Options    DeltaOptions // options the deltas were computed with
 }
 
 type SurveyDeltaMap map[SurveyKey]SurveyDeltaStats
@@ -52,13 +89,58 @@ type SurveyInfo struct {
 	FirmwareVersion    string
 	Filename           string
 	Timestamp          int
+	DateOrder          DateOrder      // order of the day and month in the file timestamps
+	Location           *time.Location // timezone the timestamps were read in
 	Surveys            SurveyMap
+	Rounds             []SurveyRound  // the same samples, per scan round
+	Sources            []SurveySource // files a merged survey was built from
+	Calibration        *Calibration   // device profile applied to the levels, if any
+	Warnings           []ParseWarning // rows skipped in lenient mode
 }
 
 type SurveyMap map[SurveyKey]SurveyDataSlice
 
 type SurveyDataSlice []SurveyData
 
+// OptionalFloat is a measurement that a row may leave unreported ("-"), which
+// must not be confused with a real zero.
+type OptionalFloat struct {
+	Value float64
+	Valid bool
+}
+
+// ReportedFloat returns a valid OptionalFloat holding v.
+func ReportedFloat(v float64) OptionalFloat {
+	return OptionalFloat{Value: v, Valid: true}
+}
+
+// OptionalInt is an identifier or count that a row may leave unreported.
+type OptionalInt struct {
+	Value int
+	Valid bool
+}
+
+// BSIC is the GSM base station identity code, reported as a decimal 0-63
+// made of the network colour code and the base station colour code.
+type BSIC OptionalInt
+
+// NCC returns the network colour code, the upper three bits of the BSIC.
+func (b BSIC) NCC() int {
+	return b.Value >> 3
+}
+
+// BCC returns the base station colour code, the lower three bits of the BSIC.
+func (b BSIC) BCC() int {
+	return b.Value & 7
+}
+
+func (b BSIC) String() string {
+	if !b.Valid {
+		return notReported
+	}
+	return fmt.Sprintf("%d (NCC %d, BCC %d)", b.Value, b.NCC(), b.BCC())
+}
+
 type SurveyData struct {
 	Survey     int
 	Timestamp  time.Time
@@ -66,48 +148,61 @@ type SurveyData struct {
 	Index      int
 	XRFCN      int
 	DBM        float64
-	Percentage float64
-	RSSI       float64
+	Percentage OptionalFloat // Siretta signal level in %
+	RSSI       OptionalFloat // Siretta RSSI scale
 	MCC        int
 	MNC        int
 	CellID     int
 	LACTAC     int
 	BandNum    int
 	Band       int
-	BSIC       string
-	SCR        string
-	ECIO       string
-	RSCP       float64
+	BSIC       BSIC
+	SCR        OptionalInt   // 3G primary scrambling code
+	ECIO       OptionalFloat // 3G Ec/Io in dB
+	RSCP       OptionalFloat
 	PCI        int
-	RSRP       float64
-	RSRQ       float64
+	RSRP       OptionalFloat
+	RSRQ       OptionalFloat
 	BW         int
 	DL         float64
 	UL         float64
+	SINR       OptionalFloat // 5G SS-SINR, or LTE SNR when the source reports it, in dB
+	SCS        int           // 5G subcarrier spacing in kHz
+	Latitude   OptionalFloat // GPS position, for sources that log it
+	Longitude  OptionalFloat
 	NetName    string
 	Signal     string
+	Extra      map[string]string // columns unknown to this version, by header name
 }
 
 type SurveySummary struct {
-	SurveyType string
-	Stat       SurveyStatsMap
-	Min        float64
-	Max        float64
+	SurveyType  string
+	Stat        SurveyStatsMap
+	Min         float64
+	Max         float64
+	Warnings    []ParseWarning // rows skipped while reading the survey
+	Unsupported []SurveyKey    // cells of a network type without a MetricCalculator
+	Options     StatOptions    // options the Stats were computed with
 }
 
 type Stats struct {
 	Number            uint
+	Censored          uint // floor readings among Number, see SurveyData.Observation
 	Mean              float64
+	CILow             float64 // confidence interval of Mean, see StatOptions
+	CIHigh            float64
 	Median            float64
 	Mode              float64
 	Range             float64
-	Quartiles         float64
+	Quartiles         [3]float64 // first quartile, median and third quartile
+	Percentiles       [7]float64 // at PercentileRanks
 	Min               float64
 	Max               float64
 	Variance          float64
 	Skewness          float64
 	Kurtosis          float64
 	StandardDeviation float64
+	Samples           []Observation // observations the Stats were computed from
 }
 type SurveyStats map[string]Stats
 
@@ -116,14 +211,25 @@ type SurveyStatsMap map[SurveyKey]SurveyStats
 // Delta statistics
 
 type DeltaStats struct {
-	Number1                uint
-	Number2                uint
+	Number1              uint
+	Number2              uint
+	TTestValue           float64
+	TTestPValue          float64
+	UValue               float64 // Mann–Whitney U
+	UPValue              float64
+	CohensD              float64
+	EffectSizeR          float64 // effect size r = t/√(t²+ν) of the t-test
+	PValue               float64 // p-value of Test
+	Test                 DeltaTest
+	AreSignificantlyDiff bool
+	Alpha                float64
+	Delta                float64
+	CILow                float64 // bootstrap interval of Delta at 1 - Alpha
+	CIHigh               float64
+
+	// Deprecated: the two surveys are independent samples with no pairing to
+	// correlate; CalculateDelta leaves it at 0. See EffectSizeR.
 	CorrelationCoefficient float64
-	TTestValue             float64
-	PValue                 float64
-	AreSignificantlyDiff   bool
-	Alpha                  float64
-	Delta                  float64
 }
 
 type SurveyDeltaStats map[string]DeltaStats
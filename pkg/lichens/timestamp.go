@@ -0,0 +1,101 @@
+package lichens
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DateOrder tells whether survey timestamps put the day or the month first.
+type DateOrder int
+
+const (
+	// DateAuto detects the order from the timestamps of the file.
+	DateAuto DateOrder = iota
+	// DateDayFirst reads "24/03/23 09:20:03" as 24 March 2023.
+	DateDayFirst
+	// DateMonthFirst reads "03/24/23 09:20:03" as 24 March 2023.
+	DateMonthFirst
+)
+
+const (
+	dayFirstLayout   = "02/01/06 15:04:05"
+	monthFirstLayout = "01/02/06 15:04:05"
+)
+
+// ParseDateOrder converts "auto", "day" or "month" to a DateOrder.
+func ParseDateOrder(s string) (DateOrder, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "auto":
+		return DateAuto, nil
+	case "day", "dmy", "day-first":
+		return DateDayFirst, nil
+	case "month", "mdy", "month-first":
+		return DateMonthFirst, nil
+	default:
+		return DateAuto, fmt.Errorf("unknown date order %q, expected auto, day or month", s)
+	}
+}
+
+func (o DateOrder) String() string {
+	switch o {
+	case DateDayFirst:
+		return "day"
+	case DateMonthFirst:
+		return "month"
+	default:
+		return "auto"
+	}
+}
+
+// Layout returns the time layout of the order. DateAuto falls back to the
+// GRAPHYTE day-first layout.
+func (o DateOrder) Layout() string {
+	if o == DateMonthFirst {
+		return monthFirstLayout
+	}
+	return dayFirstLayout
+}
+
+// DetectDateOrder picks the order that parses every non-empty timestamp. When
+// both fit, as with days up to the 12th, the GRAPHYTE day-first order wins.
+func DetectDateOrder(timestamps []string) DateOrder {
+	dayFirst, monthFirst := true, true
+	for _, value := range timestamps {
+		if value == "" {
+			continue
+		}
+		if dayFirst {
+			if _, err := time.Parse(dayFirstLayout, value); err != nil {
+				dayFirst = false
+			}
+		}
+		if monthFirst {
+			if _, err := time.Parse(monthFirstLayout, value); err != nil {
+				monthFirst = false
+			}
+		}
+	}
+	if monthFirst && !dayFirst {
+		return DateMonthFirst
+	}
+	return DateDayFirst
+}
+
+// SampleTimes returns the distinct sample timestamps of the survey in
+// chronological order.
+func (s SurveyInfo) SampleTimes() []time.Time {
+	seen := make(map[time.Time]bool)
+	var times []time.Time
+	for _, slice := range s.Surveys {
+		for _, data := range slice {
+			if !seen[data.Timestamp] {
+				seen[data.Timestamp] = true
+				times = append(times, data.Timestamp)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
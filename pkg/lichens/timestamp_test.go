@@ -0,0 +1,68 @@
+package lichens
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectDateOrder(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		timestamps []string
+		want       DateOrder
+	}{
+		{"day first", []string{"24/03/23 09:20:03", "01/04/23 10:00:00"}, DateDayFirst},
+		{"month first", []string{"03/24/23 09:20:03", "04/01/23 10:00:00"}, DateMonthFirst},
+		{"ambiguous", []string{"01/02/23 09:20:03", "12/11/23 10:00:00"}, DateDayFirst},
+		{"month first late in the file", []string{"01/02/23 09:20:03", "", "01/13/23 10:00:00"}, DateMonthFirst},
+		{"neither", []string{"24/03/23 09:20:03", "03/24/23 09:20:03"}, DateDayFirst},
+		{"empty", nil, DateDayFirst},
+	} {
+		if got := DetectDateOrder(test.timestamps); got != test.want {
+			t.Errorf("%s: DetectDateOrder = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParseSurveyDateOrder(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+	columns := "Survey:,Timestamp:,Network:,CellID:,Band:,dBm:,Net Name:"
+	// 01/02/23 alone is ambiguous; 01/13/23 makes the file month-first.
+	input := strings.Replace(testSurvey(columns,
+		"1,01/02/23 09:20:03,4G,19772943,2600 MHz,-33,Orange",
+		"2,01/13/23 09:21:03,4G,19772943,2600 MHz,-34,Orange",
+	), "File Created,24/03/23 09:20:03", "File Created,01/02/23 09:20:03", 1)
+
+	for _, test := range []struct {
+		name  string
+		opts  ParseOptions
+		order DateOrder
+		first time.Time
+	}{
+		{"auto", ParseOptions{}, DateMonthFirst, time.Date(2023, 1, 2, 9, 20, 3, 0, time.UTC)},
+		{"location", ParseOptions{Location: paris}, DateMonthFirst, time.Date(2023, 1, 2, 8, 20, 3, 0, time.UTC)},
+	} {
+		survey, err := ParseSurvey(strings.NewReader(input), test.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if survey.DateOrder != test.order {
+			t.Errorf("%s: DateOrder = %v, want %v", test.name, survey.DateOrder, test.order)
+		}
+		if !survey.FileCreated.Equal(test.first) {
+			t.Errorf("%s: FileCreated = %v, want %v", test.name, survey.FileCreated, test.first)
+		}
+		if times := survey.SampleTimes(); len(times) != 2 || !times[0].Equal(test.first) {
+			t.Errorf("%s: SampleTimes = %v, want %v first", test.name, times, test.first)
+		}
+	}
+
+	// Forced day-first, 01/13/23 has no month 13.
+	if _, err := ParseSurvey(strings.NewReader(input), ParseOptions{DateOrder: DateDayFirst}); err == nil {
+		t.Error("no error reading 01/13/23 day-first")
+	}
+}
@@ -0,0 +1,219 @@
+package lichens
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Severity grades a validation finding.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "ERROR"
+	case SeverityWarning:
+		return "WARNING"
+	default:
+		return "INFO"
+	}
+}
+
+// Finding is one problem found by ValidateSurvey. Rounds lists the first
+// rounds concerned, when the check is about samples.
+type Finding struct {
+	Severity Severity
+	Check    string
+	Message  string
+	Count    int
+	Rounds   []int
+}
+
+// ValidationOptions sets the thresholds of ValidateSurvey.
+type ValidationOptions struct {
+	MinRounds   int           // fewer rounds is an error
+	MinDuration time.Duration // a shorter session is an error
+	PassScore   int           // lower scores fail
+}
+
+// DefaultValidationOptions returns the thresholds used for billable surveys.
+func DefaultValidationOptions() ValidationOptions {
+	return ValidationOptions{MinRounds: 10, MinDuration: 5 * time.Minute, PassScore: 60}
+}
+
+// ValidationReport is the quality score of a survey, out of 100, with the
+// findings it was derived from.
+type ValidationReport struct {
+	Name     string
+	Score    int
+	Passed   bool
+	Findings []Finding
+}
+
+// Score penalties per finding.
+const (
+	errorPenalty   = 25
+	warningPenalty = 10
+)
+
+// Valid level ranges, 3GPP TS 36.133 reporting ranges.
+const (
+	minRSRP = FloorRSRP
+	maxRSRP = -44.0
+	minRSRQ = -20.0
+	maxRSRQ = -3.0
+)
+
+// maxFindingRounds caps the rounds listed in a finding.
+const maxFindingRounds = 5
+
+// ValidateSurvey checks that a survey is usable: a complete header block,
+// enough rounds over a long enough session, timestamps that do not go back,
+// no Index repeated within a round and levels within their reporting range.
+// Sentinel floor readings and rows skipped by the reader are reported too.
+// The survey passes with no error finding and a score of at least PassScore.
+func ValidateSurvey(name string, s SurveyInfo, opts ValidationOptions) ValidationReport {
+	report := ValidationReport{Name: name}
+	add := func(severity Severity, check, format string, args ...interface{}) {
+		report.Findings = append(report.Findings, Finding{Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Header block
+	if s.SurveyType == "" {
+		add(SeverityError, "header", "survey type missing")
+	}
+	if s.FileCreated.IsZero() {
+		add(SeverityError, "header", "file creation time missing")
+	}
+	if imei := NormaliseIMEI(s.IMEINumber); imei == "" {
+		add(SeverityWarning, "header", "IMEI missing")
+	} else if len(imei) != 15 || strings.Trim(imei, "0123456789") != "" {
+		add(SeverityWarning, "header", "IMEI %q is not 15 digits", imei)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"hardware version", s.HardwareVersion},
+		{"application version", s.ApplicationVersion},
+		{"firmware version", s.FirmwareVersion},
+	} {
+		if strings.Trim(field.value, "' ") == "" {
+			add(SeverityInfo, "header", "%s missing", field.name)
+		}
+	}
+
+	// Session
+	rounds := s.Rounds
+	if rounds == nil {
+		rounds = BuildRounds(s.Surveys)
+	}
+	if len(rounds) < opts.MinRounds {
+		add(SeverityError, "rounds", "%d rounds, at least %d required", len(rounds), opts.MinRounds)
+	}
+	if len(rounds) > 0 {
+		first, last := rounds[0].Start, rounds[0].Start
+		for _, round := range rounds {
+			if round.Start.Before(first) {
+				first = round.Start
+			}
+			if round.Start.After(last) {
+				last = round.Start
+			}
+		}
+		if duration := last.Sub(first); duration < opts.MinDuration {
+			add(SeverityError, "duration", "session lasts %s, at least %s required", duration, opts.MinDuration)
+		}
+	}
+
+	// Samples. Each network type of a Full survey is scanned separately, so
+	// time order and Index are checked per network type.
+	checks := map[string]*Finding{}
+	var order []string
+	flag := func(severity Severity, check, message string, round int) {
+		finding, ok := checks[check]
+		if !ok {
+			finding = &Finding{Severity: severity, Check: check, Message: message}
+			checks[check] = finding
+			order = append(order, check)
+		}
+		finding.Count++
+		if n := len(finding.Rounds); len(finding.Rounds) < maxFindingRounds && (n == 0 || finding.Rounds[n-1] != round) {
+			finding.Rounds = append(finding.Rounds, round)
+		}
+	}
+	lastStart := make(map[string]time.Time)
+	for _, round := range rounds {
+		indexes := make(map[string]bool)
+		starts := make(map[string]time.Time)
+		for _, cell := range round.Cells {
+			data := cell.Data
+			if start, ok := starts[data.Network]; !ok || data.Timestamp.Before(start) {
+				starts[data.Network] = data.Timestamp
+			}
+			index := fmt.Sprintf("%s/%d", data.Network, data.Index)
+			if indexes[index] {
+				flag(SeverityError, "index", "Index repeated within a round", round.Number)
+			}
+			indexes[index] = true
+
+			if data.RSRP.Valid && (data.RSRP.Value < minRSRP || data.RSRP.Value > maxRSRP) {
+				flag(SeverityError, "rsrp", fmt.Sprintf("RSRP outside %g…%g dBm", minRSRP, maxRSRP), round.Number)
+			}
+			if data.RSRQ.Valid && (data.RSRQ.Value < minRSRQ || data.RSRQ.Value > maxRSRQ) {
+				flag(SeverityError, "rsrq", fmt.Sprintf("RSRQ outside %g…%g dB", minRSRQ, maxRSRQ), round.Number)
+			}
+			if data.IsFloorReading() && data.RSRP.Valid && data.RSRP.Value <= FloorRSRP {
+				flag(SeverityWarning, "sentinel", fmt.Sprintf("sentinel readings, RSRP %g dBm at 0%%", minRSRP), round.Number)
+			}
+		}
+		for network, start := range starts {
+			if last, ok := lastStart[network]; ok && start.Before(last) {
+				flag(SeverityError, "timestamp", "timestamp earlier than the previous round", round.Number)
+			}
+			lastStart[network] = start
+		}
+	}
+	sort.Strings(order)
+	for _, check := range order {
+		report.Findings = append(report.Findings, *checks[check])
+	}
+
+	if len(s.Warnings) > 0 {
+		report.Findings = append(report.Findings, Finding{
+			Severity: SeverityWarning, Check: "rows", Message: "malformed rows skipped by the reader", Count: len(s.Warnings),
+		})
+	}
+
+	report.Score = 100
+	report.Passed = true
+	for _, finding := range report.Findings {
+		switch finding.Severity {
+		case SeverityError:
+			report.Score -= errorPenalty
+			report.Passed = false
+		case SeverityWarning:
+			report.Score -= warningPenalty
+		}
+	}
+	if report.Score < 0 {
+		report.Score = 0
+	}
+	if report.Score < opts.PassScore {
+		report.Passed = false
+	}
+	return report
+}
+
+// ValidationFailed returns the report of a file that could not be read.
+func ValidationFailed(name string, err error) ValidationReport {
+	return ValidationReport{
+		Name:     name,
+		Findings: []Finding{{Severity: SeverityError, Check: "read", Message: err.Error()}},
+	}
+}
@@ -0,0 +1,124 @@
+package lichens
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validateRow returns testRow as Index index of round survey, taken minute
+// minutes after 09:20.
+func validateRow(survey, minute, index int) string {
+	row := strings.Replace(testRow, "1,24/03/23 09:20:03,4G,1,", fmt.Sprintf("%d,24/03/23 09:%02d:03,4G,%d,", survey, 20+minute, index), 1)
+	return strings.Replace(row, ",19772943,", fmt.Sprintf(",%d,", 19772943+index), 1)
+}
+
+func validateSurvey(t *testing.T, lines ...string) SurveyInfo {
+	t.Helper()
+	survey, err := ParseSurvey(strings.NewReader(testSurvey(lines...)), ParseOptions{Name: "L3240918.csv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return survey
+}
+
+func findingChecks(report ValidationReport) map[string]Severity {
+	checks := make(map[string]Severity)
+	for _, finding := range report.Findings {
+		checks[finding.Check] = finding.Severity
+	}
+	return checks
+}
+
+func TestValidateSurveyClean(t *testing.T) {
+	lines := []string{testColumns}
+	for round := 1; round <= 10; round++ {
+		lines = append(lines, validateRow(round, round, 1), validateRow(round, round, 2))
+	}
+	report := ValidateSurvey("clean", validateSurvey(t, lines...), DefaultValidationOptions())
+	if report.Score != 100 || !report.Passed || len(report.Findings) != 0 {
+		t.Errorf("clean survey scored %d, passed %v, findings %+v, want 100, true and none", report.Score, report.Passed, report.Findings)
+	}
+
+	// The device logs sentinel floor readings: a warning, but the survey passes.
+	report = ValidateSurvey("L3240918.CSV", readTestSurvey(t, "../../L3240918.CSV"), DefaultValidationOptions())
+	if want := map[string]Severity{"sentinel": SeverityWarning}; !reflect.DeepEqual(findingChecks(report), want) {
+		t.Errorf("L3240918.CSV findings %+v, want %v", report.Findings, want)
+	}
+	if report.Score != 100-warningPenalty || !report.Passed {
+		t.Errorf("L3240918.CSV scored %d, passed %v, want %d and true", report.Score, report.Passed, 100-warningPenalty)
+	}
+}
+
+func TestValidateSurveyDefective(t *testing.T) {
+	highRSRP := strings.Replace(validateRow(1, 0, 2), ",391,-73,", ",391,-30,", 1)
+	sentinel := strings.Replace(strings.Replace(validateRow(1, 0, 3), ",-33,89,", ",-33,0,", 1), ",391,-73,", ",391,-140,", 1)
+	survey := validateSurvey(t,
+		testColumns,
+		validateRow(1, 0, 1),
+		validateRow(1, 0, 1),
+		highRSRP,
+		sentinel,
+		validateRow(2, 1, 1),
+	)
+
+	tests := []struct {
+		name   string
+		opts   ValidationOptions
+		checks map[string]Severity
+		score  int
+	}{
+		{
+			"default thresholds",
+			DefaultValidationOptions(),
+			map[string]Severity{
+				"rounds": SeverityError, "duration": SeverityError,
+				"index": SeverityError, "rsrp": SeverityError, "sentinel": SeverityWarning,
+			},
+			0,
+		},
+		{
+			"session thresholds met",
+			ValidationOptions{MinRounds: 2, MinDuration: time.Minute, PassScore: 60},
+			map[string]Severity{"index": SeverityError, "rsrp": SeverityError, "sentinel": SeverityWarning},
+			100 - 2*errorPenalty - warningPenalty,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := ValidateSurvey("defective", survey, test.opts)
+			if checks := findingChecks(report); !reflect.DeepEqual(checks, test.checks) {
+				t.Errorf("findings %+v, want %v", report.Findings, test.checks)
+			}
+			if report.Score != test.score || report.Passed {
+				t.Errorf("scored %d, passed %v, want %d and false", report.Score, report.Passed, test.score)
+			}
+			for _, finding := range report.Findings {
+				if finding.Check == "index" && (finding.Count != 1 || !reflect.DeepEqual(finding.Rounds, []int{1})) {
+					t.Errorf("index finding %+v, want 1 sample in round 1", finding)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSurveyPassScore(t *testing.T) {
+	lines := []string{testColumns}
+	for round := 1; round <= 10; round++ {
+		lines = append(lines, validateRow(round, round, 1))
+	}
+	// Warnings alone fail a survey once the score drops below PassScore.
+	lines = append(lines, "1,24/03/23 09:21:03,4G,x")
+	survey, err := ParseSurvey(strings.NewReader(testSurvey(lines...)), ParseOptions{Name: "L3240918.csv", Mode: ParseLenient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultValidationOptions()
+	opts.PassScore = 95
+	report := ValidateSurvey("warnings", survey, opts)
+	if report.Score != 100-warningPenalty || report.Passed {
+		t.Errorf("scored %d, passed %v, want %d and false", report.Score, report.Passed, 100-warningPenalty)
+	}
+}
@@ -0,0 +1,198 @@
+package lichens
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteSirettaCSV writes a survey in the GRAPHYTE layout read by ReadMultiCSV:
+// the 14 line header block, then each scan round preceded by the column
+// header, with the CRLF line endings of the device. The rounds are rebuilt
+// from s.Surveys, so a filtered or merged map is written as it stands.
+// Timestamps use the DateOrder and Location of the survey. SINR, SCS and the
+// Extra columns are appended after the Siretta columns when a sample carries
+// them.
+func WriteSirettaCSV(w io.Writer, s SurveyInfo) error {
+	location := s.Location
+	if location == nil {
+		location = s.FileCreated.Location()
+	}
+	layout := s.DateOrder.Layout()
+
+	rounds := BuildRounds(s.Surveys)
+	header, extras := sirettaHeader(rounds)
+
+	// The header block is written as is: csv.Writer would quote the space
+	// GRAPHYTE puts before the survey type.
+	for _, line := range [][2]string{
+		{"=================", ""},
+		{"Siretta Limited", ""},
+		{"=================", ""},
+		{"GRAPHYTE Network Survey Results", ""},
+		{"www.siretta.com", ""},
+		{"+44 1189 769 000", ""},
+		{"Survey Type", " " + s.SurveyType},
+		{"File Created", s.FileCreated.In(location).Format(layout)},
+		{"IMEI Number", textCell(s.IMEINumber)},
+		{"Hardware Version", textCell(s.HardwareVersion)},
+		{"Application Version", textCell(s.ApplicationVersion)},
+		{"Firmware Version", textCell(s.FirmwareVersion)},
+		{"Filename", textCell(s.Filename)},
+		{"Timestamp", textCell(strconv.Itoa(s.Timestamp))},
+	} {
+		text := line[0]
+		if line[1] != "" {
+			text += "," + strings.NewReplacer(",", " ", "\n", " ").Replace(line[1])
+		}
+		if _, err := io.WriteString(w, text+"\r\n"); err != nil {
+			return err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+	for _, round := range rounds {
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, cell := range round.Cells {
+			timestamp := cell.Data.Timestamp.In(location).Format(layout)
+			record := sirettaRecord(cell.Data, timestamp, header, extras)
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// textCell prefixes a header value with the quote Siretta uses to keep
+// spreadsheets from reading it as a number.
+func textCell(value string) string {
+	if strings.HasPrefix(value, "'") {
+		return value
+	}
+	return "'" + value
+}
+
+// sirettaHeader returns the column header row for the samples of rounds and
+// the names of the Extra columns it ends with.
+func sirettaHeader(rounds []SurveyRound) ([]string, []string) {
+	columns := append([]string(nil), sirettaColumns...)
+	var sinr, scs bool
+	extraSet := make(map[string]bool)
+	for _, round := range rounds {
+		for _, cell := range round.Cells {
+			sinr = sinr || cell.Data.SINR.Valid
+			scs = scs || cell.Data.SCS != 0
+			for name := range cell.Data.Extra {
+				extraSet[name] = true
+			}
+		}
+	}
+	if sinr {
+		columns = append(columns, ColSINR)
+	}
+	if scs {
+		columns = append(columns, ColSCS)
+	}
+	extras := make([]string, 0, len(extraSet))
+	for name := range extraSet {
+		extras = append(extras, name)
+	}
+	sort.Strings(extras)
+	columns = append(columns, extras...)
+
+	header := make([]string, len(columns))
+	for i, name := range columns {
+		header[i] = name + ":"
+	}
+	return header, extras
+}
+
+// sirettaRecord formats one sample in the order of header. Values the RAT
+// does not report are written as "-".
+func sirettaRecord(d SurveyData, timestamp string, header []string, extras []string) []string {
+	values := map[string]string{
+		ColSurvey:     strconv.Itoa(d.Survey),
+		ColTimestamp:  timestamp,
+		ColNetwork:    d.Network,
+		ColIndex:      strconv.Itoa(d.Index),
+		ColXRFCN:      strconv.Itoa(d.XRFCN),
+		ColDBM:        formatFloat(d.DBM),
+		ColPercentage: formatOptionalFloat(d.Percentage),
+		ColRSSI:       formatOptionalFloat(d.RSSI),
+		ColMCC:        strconv.Itoa(d.MCC),
+		ColMNC:        fmt.Sprintf("%02d", d.MNC),
+		ColCellID:     strconv.Itoa(d.CellID),
+		ColLACTAC:     strconv.Itoa(d.LACTAC),
+		ColBandNum:    strconv.Itoa(d.BandNum),
+		ColBand:       fmt.Sprintf("%d MHz", d.Band),
+		ColBSIC:       formatOptionalInt(OptionalInt(d.BSIC)),
+		ColSCR:        formatOptionalInt(d.SCR),
+		ColECIO:       formatOptionalFloat(d.ECIO),
+		ColRSCP:       formatOptionalFloat(d.RSCP),
+		ColPCI:        notReported,
+		ColRSRP:       formatOptionalFloat(d.RSRP),
+		ColRSRQ:       notReported,
+		ColBW:         strconv.Itoa(d.BW),
+		ColDL:         formatFrequency(d.DL),
+		ColUL:         formatFrequency(d.UL),
+		ColNetName:    d.NetName,
+		ColSignal:     d.Signal,
+		ColSINR:       formatOptionalFloat(d.SINR),
+		ColSCS:        notReported,
+	}
+	if d.Network == "4G" || d.Network == "5G" {
+		values[ColPCI] = strconv.Itoa(d.PCI)
+	}
+	if d.RSRQ.Valid {
+		values[ColRSRQ] = strconv.FormatFloat(d.RSRQ.Value, 'f', 1, 64)
+	}
+	if d.SCS != 0 {
+		values[ColSCS] = strconv.Itoa(d.SCS)
+	}
+	for _, name := range extras {
+		if value, ok := d.Extra[name]; ok {
+			values[name] = value
+		}
+	}
+
+	record := make([]string, len(header))
+	for i, column := range header {
+		record[i] = values[strings.TrimSuffix(column, ":")]
+	}
+	return record
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// formatFrequency writes a channel frequency in MHz as GRAPHYTE does, with
+// one decimal for whole megahertz and three otherwise.
+func formatFrequency(v float64) string {
+	if v == float64(int64(v)) {
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+func formatOptionalFloat(v OptionalFloat) string {
+	if !v.Valid {
+		return notReported
+	}
+	return formatFloat(v.Value)
+}
+
+func formatOptionalInt(v OptionalInt) string {
+	if !v.Valid {
+		return notReported
+	}
+	return strconv.Itoa(v.Value)
+}
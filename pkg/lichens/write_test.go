@@ -0,0 +1,66 @@
+package lichens
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWriteSirettaCSVRoundTrip(t *testing.T) {
+	for _, filename := range []string{"../../L3240918.CSV", "../../L3241330.CSV"} {
+		original, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		survey, err := ParseSurvey(bytes.NewReader(original), ParseOptions{})
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+
+		var written bytes.Buffer
+		if err := WriteSirettaCSV(&written, survey); err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		reread, err := ParseSurvey(bytes.NewReader(written.Bytes()), ParseOptions{})
+		if err != nil {
+			t.Fatalf("%s written: %v", filename, err)
+		}
+		if !reflect.DeepEqual(reread, survey) {
+			t.Errorf("%s: survey read back differs", filename)
+		}
+
+		// The header block is written as read; the rows of a round are
+		// written by Index, where the device does not keep that order.
+		originalLines := strings.SplitAfter(string(original), "\r\n")
+		writtenLines := strings.SplitAfter(written.String(), "\r\n")
+		if !reflect.DeepEqual(writtenLines[:headerRows], originalLines[:headerRows]) {
+			t.Errorf("%s: header block written as %q, want %q", filename, writtenLines[:headerRows], originalLines[:headerRows])
+		}
+		sort.Strings(originalLines)
+		sort.Strings(writtenLines)
+		if !reflect.DeepEqual(writtenLines, originalLines) {
+			t.Errorf("%s: written lines differ from the original", filename)
+		}
+	}
+}
+
+func TestWriteSirettaCSVTimestampHeader(t *testing.T) {
+	input := strings.Replace(testSurvey(testColumns, testRow), "Timestamp,'0", "Timestamp,'1679649603", 1)
+	survey, err := ParseSurvey(strings.NewReader(input), ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if survey.Timestamp != 1679649603 {
+		t.Errorf("Timestamp = %d, want 1679649603", survey.Timestamp)
+	}
+	var written bytes.Buffer
+	if err := WriteSirettaCSV(&written, survey); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(written.String(), "\r\nTimestamp,'1679649603\r\n") {
+		t.Errorf("Timestamp header not written back:\n%s", written.String())
+	}
+}
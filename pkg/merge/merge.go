@@ -0,0 +1,52 @@
+package merge
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lichensio/slichens/pkg/lichens"
+)
+
+/*
+ * Copyright © 2023 LICHENS http://www.lichens.io
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// ProcessMerge merges the survey files matched by pattern and writes the
+// result as a siretta csv file to output, or to stdout for "-". Every file
+// must be readable: a merge of part of the files is not written.
+func ProcessMerge(pattern, output string, opts lichens.ParseOptions, mergeOpts lichens.MergeOptions) (lichens.SurveyInfo, []lichens.MergeConflict, error) {
+	if pattern == "" {
+		return lichens.SurveyInfo{}, nil, fmt.Errorf("Please provide siretta survey file names, L____.CSV")
+	}
+	if output == "" {
+		return lichens.SurveyInfo{}, nil, fmt.Errorf("Please provide an output file name")
+	}
+
+	surveys, err := lichens.ReadSurveyDirWithOptions(pattern, 0, opts)
+	if err != nil {
+		return lichens.SurveyInfo{}, nil, fmt.Errorf("Error reading CSV: %w", err)
+	}
+	merged, conflicts, err := lichens.Merge(surveys, mergeOpts)
+	if err != nil {
+		return merged, conflicts, err
+	}
+
+	if output == lichens.StdinName {
+		return merged, conflicts, lichens.WriteSirettaCSV(os.Stdout, merged)
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		return merged, conflicts, err
+	}
+	if err := lichens.WriteSirettaCSV(file, merged); err != nil {
+		file.Close()
+		return merged, conflicts, err
+	}
+	return merged, conflicts, file.Close()
+}
@@ -15,34 +15,59 @@ import (
  * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
  */
 
-func ProcessSurvey(filename string, allStat, freq, sample bool) (lichens.SurveySummary, error) {
+// ProcessSurvey summarises the survey file, or the files matched by a
+// directory or glob merged into one survey, named by filename. Files from
+// different devices or firmwares are merged with a warning.
+func ProcessSurvey(filename string, opts lichens.ParseOptions, statOpts lichens.StatOptions, allStat, freq, sample bool) (lichens.SurveySummary, error) {
 	if filename == "" {
 		fmt.Println("Please provide a siretta survey file name, L____.CSV")
 		return lichens.SurveySummary{}, fmt.Errorf("Please provide a siretta survey file name, L____.CSV")
 	}
 
-	// Get the survey data from the file
-	survey, err := lichens.ReadMultiCSV(filename)
+	// Get the survey data from the files
+	surveys, err := lichens.ReadSurveyDirWithOptions(filename, 0, opts)
 	if err != nil {
 		fmt.Println("Error reading CSV:", err)
-		return lichens.SurveySummary{}, fmt.Errorf("Error reading CSV:", err)
+		return lichens.SurveySummary{}, fmt.Errorf("Error reading CSV: %w", err)
 	}
+	survey, conflicts, err := lichens.Merge(surveys, lichens.MergeOptions{Mode: lichens.MergeWarn})
+	if err != nil {
+		return lichens.SurveySummary{}, err
+	}
+	for _, conflict := range conflicts {
+		fmt.Println("Warning, merged surveys:", conflict)
+	}
+
+	return summarise(survey, statOpts, sample), nil
+}
 
-	surveys := survey.Surveys
+// ProcessSurveys summarises each survey file matched by pattern separately,
+// reading them with the given number of workers. Files that cannot be read
+// are reported in the returned error, the others are still summarised.
+func ProcessSurveys(pattern string, opts lichens.ParseOptions, statOpts lichens.StatOptions, workers int, sample bool) (map[string]lichens.SurveySummary, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("Please provide a siretta survey file name, L____.CSV")
+	}
 
+	surveys, err := lichens.ReadSurveyDirWithOptions(pattern, workers, opts)
+	summaries := make(map[string]lichens.SurveySummary, len(surveys))
+	for filename, survey := range surveys {
+		summaries[filename] = summarise(survey, statOpts, sample)
+	}
+	return summaries, err
+}
+
+func summarise(survey lichens.SurveyInfo, statOpts lichens.StatOptions, sample bool) lichens.SurveySummary {
 	if sample {
-		surveys = lichens.SurveySampleRemove(surveys, lichens.MinimumSampleCount) // Removed magic number, used '2' directly here as specified in your original code
+		survey.Surveys = lichens.SurveySampleRemove(survey.Surveys, lichens.MinimumSampleCount)
 	}
 
-	summary := lichens.SurveyStatGen(survey)
+	summary := lichens.SurveyStatGen(survey, statOpts)
 	key := &lichens.SurveyKey{
 		Band:    0,
 		CellID:  0,
 		NetName: "",
 	}
 	summary.Stat = lichens.SelectStats(summary.Stat, *key)
-
-	// currentTime := time.Now()
-	// SurveyConsolePrint("Survey", currentTime, allStat, freq, keys, summary)
-	return summary, nil
+	return summary
 }
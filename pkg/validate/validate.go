@@ -0,0 +1,48 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/lichensio/slichens/pkg/lichens"
+)
+
+/*
+ * Copyright © 2023 LICHENS http://www.lichens.io
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+// ProcessValidate validates each survey matched by pattern and prints its
+// report. A file that cannot be read gets a failed report. The raw levels are
+// checked: configured calibration profiles are not applied. It returns the
+// reports in filename order.
+func ProcessValidate(pattern string, opts lichens.ParseOptions, validationOpts lichens.ValidationOptions) ([]lichens.ValidationReport, error) {
+	opts.Calibration = nil
+	if pattern == "" {
+		return nil, fmt.Errorf("Please provide a siretta survey file name, L____.CSV")
+	}
+	filenames, err := lichens.ExpandSurveyPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []lichens.ValidationReport
+	for _, filename := range filenames {
+		surveys, err := lichens.ReadSurveyFile(filename, opts)
+		if err != nil {
+			reports = append(reports, lichens.ValidationFailed(filename, err))
+		}
+		for _, name := range lichens.SortedFilenames(surveys) {
+			reports = append(reports, lichens.ValidateSurvey(name, surveys[name], validationOpts))
+		}
+	}
+
+	for _, report := range reports {
+		lichens.TablePrintValidation(report)
+	}
+	return reports, nil
+}